@@ -2,10 +2,17 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
-	"log"
+	"iter"
+	"log/slog"
+	"math"
+	"math/rand/v2"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,7 +20,9 @@ import (
 	dlms "github.com/Gurux/gxdlms-go"
 	"github.com/Gurux/gxdlms-go/enums"
 	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/settings"
 	"github.com/Gurux/gxdlms-go/types"
+	"github.com/Gurux/gxnet-go"
 	"github.com/Gurux/gxserial-go"
 )
 
@@ -21,43 +30,127 @@ type GXDLMSReader struct {
 	WaitTime          int
 	RetryCount        int
 	InvocationCounter string
+	// Context stops the reads that are made without the context when it's cancelled, e.g. on SIGINT.
+	// Background context is used if it's nil.
+	Context context.Context
+	// AutoReferencing retries the association with SN referencing if LN referencing is not supported.
+	AutoReferencing bool
+	// RefreshCache reads the association view from the meter even if the cache file exists.
+	RefreshCache bool
+	// ICStore saves the invocation counter after the association and loads it before the next one.
+	// Counter is not saved if it's nil.
+	ICStore *GXInvocationCounterStore
+	// BestEffort continues with the received objects if the association view is read only partially.
+	BestEffort bool
+	// StrictTitle closes the connection if the meter system title in AARE is not the recipient system title.
+	StrictTitle bool
+	// ProfileWaitTime is the reply wait time in milliseconds for profile generic buffer reads.
+	// WaitTime is used if it's zero.
+	ProfileWaitTime int
+	// ReconnectCount is how many times the association is re-established when the meter stops
+	// replying while all objects are read. Zero disables reconnecting.
+	ReconnectCount int
+	// TempRetryCount is how many times the operation is retried when the meter returns temporary failure.
+	// Rejected operation is retried at least once.
+	TempRetryCount int
+	// TempWaitTime is the delay in milliseconds before the first retry when the meter is busy.
+	// It grows with BackoffMultiplier until BackoffMax is reached.
+	TempWaitTime int
+	// Jitter is the maximum random delay in milliseconds that is added to the retry delays.
+	Jitter int
+	// BackoffBase is the delay in milliseconds before the first resend.
+	BackoffBase int
+	// BackoffMax is the maximum delay in milliseconds between the resends.
+	BackoffMax int
+	// BackoffMultiplier multiplies the delay after each resend.
+	BackoffMultiplier float64
+	// RxChunk is the minimum number of bytes waited in one receive so several frames are received at once.
+	// Zero waits the bytes of the current frame.
+	RxChunk int
+	// Limit is the maximum number of objects that GetReadOut reads. Zero reads all objects.
+	Limit int
+	// ObjectTypes are the object types that are read. Empty reads all object types.
+	ObjectTypes []enums.ObjectType
+	// UnitMode selects the magnitude of shown energy and power values.
+	UnitMode UnitMode
+	// RowOrder is the order of the read profile generic rows.
+	RowOrder RowOrder
+	// ApplyScaler shows register values with the unit and the scaler.
+	ApplyScaler bool
+	// SkipProfiles skips the profile generic rows in ReadAll. Capture objects are still read.
+	SkipProfiles bool
+	// SkipReadOut skips the attribute read of the objects in ReadAll.
+	SkipReadOut bool
+	// RecordFrames stores sent and received frames for the session report.
+	RecordFrames bool
+	// HexDumpFile is the file where sent and received frames are written in text2pcap format.
+	// Frames are not written if it's empty.
+	HexDumpFile string
+	// Stats collects the round-trip times of the reads and the frame counts. Nothing is collected if it's nil.
+	Stats *ReadStats
+	// OnProgress is called after each object is read in ReadAll. Progress is not reported if it's nil.
+	OnProgress func(GXProgress)
+	// Sink receives the read attribute values. Values are not published if it's nil.
+	Sink GXValueSink
 
 	media          gxcommon.IGXMedia
 	trace          gxcommon.TraceLevel
 	client         *dlms.GXDLMSSecureClient
-	traceFile      string
+	logger         *slog.Logger
+	frames         []GXTraceFrame
+	reconnects     int
+	deviceName     []byte
+	viewCount      int
+	partialView    bool
+	requestedPDU   uint16
+	progress       GXProgress
+	progressStart  time.Time
+	progressActive bool
+	secrets        *strings.Replacer
+	failures       []GXReadFailure
+	pending        []byte
 	OnNotification func(any)
 }
 
-// NewGXDLMSReader creates a new DLMS reader.
+// NewGXDLMSReader creates a new DLMS reader. Trace is written to the logger.
+// slog.Default is used if logger is nil.
 func NewGXDLMSReader(
 	client *dlms.GXDLMSSecureClient,
 	media gxcommon.IGXMedia,
 	trace gxcommon.TraceLevel,
 	invocationCounter string,
 	waitTime int,
+	logger *slog.Logger,
 ) *GXDLMSReader {
 	if waitTime <= 0 {
 		waitTime = 5000
 	}
+	if logger == nil {
+		logger = slog.Default()
+	}
 	return &GXDLMSReader{
 		WaitTime:          waitTime,
 		RetryCount:        3,
+		TempWaitTime:      1000,
+		BackoffMax:        30000,
+		BackoffMultiplier: 2,
 		InvocationCounter: invocationCounter,
 		media:             media,
 		trace:             trace,
 		client:            client,
-		traceFile:         "trace.txt",
+		logger:            logger,
 	}
 }
 
 // InitializeConnection opens the transport and performs DLMS association.
 func (r *GXDLMSReader) InitializeConnection() error {
-	r.writeTrace(fmt.Sprintf("Standard: %s", r.client.Standard().String()))
+	r.logger.Debug("initialize connection", "standard", r.client.Standard().String())
 	r.logSecurityInfo()
+	//Bytes of the old connection are not part of the new association.
+	r.pending = nil
 
 	if !r.media.IsOpen() {
-		if err := r.media.Open(); err != nil {
+		if err := openMedia(r.media); err != nil {
 			return err
 		}
 	}
@@ -65,38 +158,95 @@ func (r *GXDLMSReader) InitializeConnection() error {
 	if err := r.updateFrameCounter(); err != nil {
 		return err
 	}
+	if err := r.loadInvocationCounter(); err != nil {
+		return fmt.Errorf("invocation counter load failed: %w", err)
+	}
 	if err := r.initializeOpticalHead(); err != nil {
 		return err
 	}
-	if err := r.SNRMRequest(); err != nil {
+	if err := r.initializePlc(); err != nil {
 		return err
 	}
+	if err := r.SNRMRequest(); err != nil {
+		return fmt.Errorf("%w: %w", ErrAssociationFailed, err)
+	}
 
 	if r.client.PreEstablishedConnection() {
+		r.logger.Debug("association is pre-established, AARQ is not sent")
 		return nil
 	}
 
 	if err := r.AarqRequest(); err != nil {
-		return err
+		if !r.AutoReferencing || !isReferencingError(err) {
+			return fmt.Errorf("%w: %w", ErrAssociationFailed, err)
+		}
+		if r.switchReferencing() != nil {
+			//Other referencing failed too. Report the reason of the first rejection.
+			return fmt.Errorf("%w: %w", ErrAssociationFailed, err)
+		}
 	}
-	r.writeTrace(fmt.Sprintf("Conformance: %s", r.client.NegotiatedConformance().String()))
+	r.logger.Debug("associated", "conformance", r.client.NegotiatedConformance().String(), "maxPduSize", r.client.MaxReceivePDUSize())
+	r.saveInvocationCounter()
 	return nil
 }
 
+// switchReferencing changes between LN and SN referencing and makes the association again.
+func (r *GXDLMSReader) switchReferencing() error {
+	ln := !r.client.UseLogicalNameReferencing()
+	r.logger.Info("referencing is not supported, retrying", "logicalNameReferencing", ln)
+	if frame, err := r.client.DisconnectRequest(); err == nil && frame != nil {
+		_ = r.ReadDLMSPacket(frame, dlms.NewGXReplyData())
+	}
+	if err := r.client.SetUseLogicalNameReferencing(ln); err != nil {
+		return err
+	}
+	if err := r.SNRMRequest(); err != nil {
+		return err
+	}
+	return r.AarqRequest()
+}
+
+// referencingFromCache returns true if the association view cache was read with LN referencing.
+// The second value is false if the cache doesn't exist or the referencing is not known.
+func referencingFromCache(path string) (bool, bool) {
+	if path == "" {
+		return false, false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return false, false
+	}
+	var objs objects.GXDLMSObjectCollection
+	if err := objs.LoadFromFile(path); err != nil {
+		return false, false
+	}
+	if len(objs.GetObjects(enums.ObjectTypeAssociationLogicalName)) != 0 {
+		return true, true
+	}
+	if len(objs.GetObjects(enums.ObjectTypeAssociationShortName)) != 0 {
+		return false, true
+	}
+	return false, false
+}
+
 func (r *GXDLMSReader) logSecurityInfo() {
 	c := r.client.Ciphering()
 	if c == nil || c.Security() == enums.SecurityNone {
 		return
 	}
-	r.writeTrace(fmt.Sprintf("Security: %s", c.Security().String()))
-	r.writeTrace("System title: " + types.ToHex(c.SystemTitle(), true))
-	r.writeTrace("Authentication key: " + types.ToHex(c.AuthenticationKey(), true))
-	r.writeTrace("Block cipher key: " + types.ToHex(c.BlockCipherKey(), true))
+	attrs := []any{
+		"security", c.Security().String(),
+		"systemTitle", types.ToHex(c.SystemTitle(), true),
+		"authenticationKey", types.ToHex(c.AuthenticationKey(), true),
+		"blockCipherKey", types.ToHex(c.BlockCipherKey(), true),
+	}
 	if dk := c.DedicatedKey(); len(dk) != 0 {
-		r.writeTrace("Dedicated key: " + types.ToHex(dk, true))
+		attrs = append(attrs, "dedicatedKey", types.ToHex(dk, true))
 	}
+	r.logger.Debug("security", attrs...)
 }
 
+// initializeOpticalHead makes IEC 62056-21 mode E handshake and changes the serial port
+// to the baud rate that the meter proposes before HDLC is used.
 func (r *GXDLMSReader) initializeOpticalHead() error {
 	if r.client.InterfaceType() != enums.InterfaceTypeHdlcWithModeE {
 		return nil
@@ -108,11 +258,42 @@ func (r *GXDLMSReader) initializeOpticalHead() error {
 	}
 
 	if !r.media.IsOpen() {
-		if err := r.media.Open(); err != nil {
+		if err := openMedia(r.media); err != nil {
 			return err
 		}
 	}
 
+	baudRate, err := r.iecHandshake()
+	if err != nil {
+		return err
+	}
+	if err = r.media.Close(); err != nil {
+		return err
+	}
+	if err = serial.SetBaudRate(baudRate); err != nil {
+		return err
+	}
+	if err = serial.SetDataBits(8); err != nil {
+		return err
+	}
+	if err = serial.SetParity(gxcommon.ParityNone); err != nil {
+		return err
+	}
+	if err = serial.SetStopBits(gxcommon.StopBitsOne); err != nil {
+		return err
+	}
+	if err = openMedia(r.media); err != nil {
+		return err
+	}
+
+	//Some meters need this sleep. Do not remove.
+	time.Sleep(800 * time.Millisecond)
+	return nil
+}
+
+// iecHandshake sends IEC identification request, acknowledges HDLC mode and returns the baud rate
+// that the meter proposes.
+func (r *GXDLMSReader) iecHandshake() (gxcommon.BaudRate, error) {
 	// Some meters need a little break before IEC handshake starts.
 	time.Sleep(time.Second)
 	data := "/?!\r\n"
@@ -124,117 +305,119 @@ func (r *GXDLMSReader) initializeOpticalHead() error {
 	unlock := r.media.GetSynchronous()
 	defer unlock()
 
-	if r.trace > gxcommon.TraceLevelInfo {
-		r.writeTrace("IEC TX: " + data)
-	}
-	if err := r.media.Send(data, ""); err != nil {
-		return err
-	}
-	succeeded, err := r.media.Receive(p)
-	if err != nil {
-		return err
-	}
-	if !succeeded {
-		_ = r.Disconnect()
-		if err = r.media.Send(data, ""); err != nil {
-			return err
+	var reply string
+	for attempt := 0; ; attempt++ {
+		r.logger.Debug("IEC frame", "direction", "TX", "bytes", len(data), "data", data)
+		if err := r.media.Send(data, ""); err != nil {
+			return 0, err
 		}
-		succeeded, err = r.media.Receive(p)
-		if err != nil {
-			return err
-		}
-		if !succeeded {
-			return errors.New("failed to receive reply from the device in given time")
-		}
-	}
-
-	reply, _ := p.Reply.(string)
-	if reply == data {
 		p.Reply = nil
-		succeeded, err = r.media.Receive(p)
+		succeeded, err := r.media.Receive(p)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		if !succeeded {
-			return errors.New("failed to receive reply from the device in given time")
+		if succeeded {
+			reply, _ = p.Reply.(string)
+			//Optical probe might echo the sent data.
+			if reply != data {
+				break
+			}
+			p.Reply = nil
+			if succeeded, err = r.media.Receive(p); err != nil {
+				return 0, err
+			}
+			if succeeded {
+				reply, _ = p.Reply.(string)
+				break
+			}
+		}
+		if attempt+1 >= r.RetryCount {
+			return 0, fmt.Errorf("meter didn't reply to IEC identification request in %d ms. Check the optical probe and that the meter supports mode E", r.WaitTime)
 		}
-		reply, _ = p.Reply.(string)
-	}
-	if r.trace > gxcommon.TraceLevelInfo {
-		r.writeTrace("IEC RX: " + reply)
 	}
+	r.logger.Debug("IEC frame", "direction", "RX", "bytes", len(reply), "data", reply)
 
 	start := strings.IndexByte(reply, '/')
 	if start < 0 || len(reply) < start+5 {
-		return errors.New("invalid response")
+		return 0, fmt.Errorf("invalid IEC identification reply %q", reply)
 	}
 	baudID := reply[start+4]
-	baudRate := 0
+	var baudRate gxcommon.BaudRate
 	switch baudID {
 	case '0':
-		baudRate = 300
+		baudRate = gxcommon.BaudRate300
 	case '1':
-		baudRate = 600
+		baudRate = gxcommon.BaudRate600
 	case '2':
-		baudRate = 1200
+		baudRate = gxcommon.BaudRate1200
 	case '3':
-		baudRate = 2400
+		baudRate = gxcommon.BaudRate2400
 	case '4':
-		baudRate = 4800
+		baudRate = gxcommon.BaudRate4800
 	case '5':
-		baudRate = 9600
+		baudRate = gxcommon.BaudRate9600
 	case '6':
-		baudRate = 19200
+		baudRate = gxcommon.BaudRate19200
 	default:
-		return errors.New("unknown baud rate")
+		return 0, fmt.Errorf("unknown baud rate identification %q in IEC reply %q", baudID, reply)
 	}
 
+	//ACK, protocol control character 2 (HDLC), baud rate and mode control character 2.
 	arr := []byte{0x06, '2', baudID, '2', 0x0D, 0x0A}
-	if err = r.media.Send(arr, ""); err != nil {
-		return err
+	r.logger.Debug("IEC frame", "direction", "TX", "bytes", len(arr), "data", types.ToHex(arr, true))
+	if err := r.media.Send(arr, ""); err != nil {
+		return 0, err
 	}
 	// Some meters need this delay after ACK before switching serial parameters.
 	time.Sleep(200 * time.Millisecond)
 	p.WaitTime = 2000
+	p.Reply = nil
 	_, _ = r.media.Receive(p)
+	return baudRate, nil
+}
 
-	if err = r.media.Close(); err != nil {
-		return err
+// imageBlockOverhead is the size of the image_block_transfer action request without the block data:
+// action-request-normal tag, type and invoke ID (3), method descriptor (9), parameters flag (1),
+// structure of two elements (2), block number as uint32 (5) and octet string tag with the length (4).
+const imageBlockOverhead = 3 + 9 + 1 + 2 + 5 + 4
+
+// imageBlockCipherOverhead is added to the ciphered request: ciphered APDU tag with the length (4),
+// security control (1), invocation counter (4) and authentication tag (12).
+const imageBlockCipherOverhead = 4 + 1 + 4 + 12
+
+// CheckImageTransfer reads image block size and image transfer enabled attributes and
+// checks that the firmware can be transferred with the negotiated PDU size.
+func (r *GXDLMSReader) CheckImageTransfer(target *objects.GXDLMSImageTransfer) error {
+	if target == nil {
+		return gxcommon.ErrInvalidArgument
 	}
-	if err = serial.SetBaudRate(gxcommon.BaudRate(baudRate)); err != nil {
+	if _, err := r.Read(target, 5); err != nil {
 		return err
 	}
-	if err = serial.SetDataBits(8); err != nil {
-		return err
+	if !target.ImageTransferEnabled {
+		return errors.New("firmware update not possible: transfer disabled")
 	}
-	if err = serial.SetParity(gxcommon.ParityNone); err != nil {
+	if _, err := r.Read(target, 2); err != nil {
 		return err
 	}
-	if err = serial.SetStopBits(gxcommon.StopBitsOne); err != nil {
-		return err
+	overhead := uint32(imageBlockOverhead)
+	if r.client.Ciphering().Security() != enums.SecurityNone {
+		overhead += imageBlockCipherOverhead
 	}
-	if err = r.media.Open(); err != nil {
-		return err
+	if pdu := uint32(r.client.MaxReceivePDUSize()); pdu != 0 && target.ImageBlockSize+overhead > pdu {
+		return fmt.Errorf("firmware update not possible: block size %d exceeds PDU size %d", target.ImageBlockSize, pdu)
 	}
-
-	//Some meters need this sleep. Do not remove.
-	time.Sleep(800 * time.Millisecond)
 	return nil
 }
 
 // ImageUpdate updates meter firmware using the image transfer object.
+// Progress of the block transfer is logged as a percentage. If the meter reboots without replying to
+// the activation, the activation is verified after the meter accepts the connection again.
 func (r *GXDLMSReader) ImageUpdate(target *objects.GXDLMSImageTransfer, identification []byte, image []byte) error {
 	if target == nil || len(identification) == 0 || len(image) == 0 {
 		return gxcommon.ErrInvalidArgument
 	}
-	if _, err := r.Read(target, 5); err != nil {
-		return err
-	}
-	if !target.ImageTransferEnabled {
-		return errors.New("image transfer is not enabled")
-	}
-
-	if _, err := r.Read(target, 2); err != nil {
+	if err := r.CheckImageTransfer(target); err != nil {
 		return err
 	}
 
@@ -251,10 +434,18 @@ func (r *GXDLMSReader) ImageUpdate(target *objects.GXDLMSImageTransfer, identifi
 	if err != nil {
 		return err
 	}
-	if _, err = r.ReadDataBlocks(frames, reply); err != nil {
-		return err
+	last := -1
+	for pos, frame := range frames {
+		reply.Clear()
+		if err = r.ReadDataBlock(frame, reply); err != nil {
+			return err
+		}
+		if percent := 100 * (pos + 1) / len(frames); percent != last {
+			last = percent
+			r.logger.Info("image transfer", "progress", percent)
+		}
 	}
-	r.writeTrace(fmt.Sprintf("Image blocks transferred: %d", imageBlockCount))
+	r.logger.Debug("image blocks transferred", "blocks", imageBlockCount)
 
 	if _, err = r.Read(target, 3); err != nil {
 		return err
@@ -271,7 +462,7 @@ func (r *GXDLMSReader) ImageUpdate(target *objects.GXDLMSImageTransfer, identifi
 		if !errors.Is(err, enums.ErrorCodeTemporaryFailure) {
 			return err
 		}
-		r.writeTrace("Image Verification temporary failed, retrying...")
+		r.logger.Debug("image verification temporary failed, retrying")
 		time.Sleep(5 * time.Second)
 	}
 
@@ -296,43 +487,303 @@ func (r *GXDLMSReader) ImageUpdate(target *objects.GXDLMSImageTransfer, identifi
 		return fmt.Errorf("image transfer status is %s", target.ImageTransferStatus.String())
 	}
 
-	//Activate the image.
-	frames, err = target.ImageActivate(r.client)
-	if err != nil {
+	//Activate the image. Meter returns temporary failure until it's ready to activate.
+	for {
+		frames, err = target.ImageActivate(r.client)
+		if err != nil {
+			return err
+		}
+		_, err = r.ReadDataBlocks(frames, reply)
+		if err == nil || !errors.Is(err, enums.ErrorCodeTemporaryFailure) {
+			break
+		}
+		r.logger.Debug("image activate temporary failed, retrying")
+		if err = sleep(r.context(), imageActivateWaitTime); err != nil {
+			return err
+		}
+	}
+	if err == nil || !isLinkError(err) {
 		return err
 	}
 	// Meters usually reboot immediately after image activation and may not respond to the request.
-	// In that case, we can wait for a while and try to establish the connection again.
-	r.ReadDataBlocks(frames, reply)
-	/*
-		for {
-			frames, err = target.ImageActivate(r.client)
-			if err != nil {
-				return err
+	// Activation is verified when the meter accepts the connection again.
+	r.logger.Info("no reply to image activate, verifying the activation", "error", err)
+	return r.verifyImageActivation(target)
+}
+
+// imageActivateWaitTime is the wait time before image activation is retried or verified.
+const imageActivateWaitTime = 5 * time.Second
+
+// verifyImageActivation connects to the meter after it has rebooted and checks that the image is activated.
+func (r *GXDLMSReader) verifyImageActivation(target *objects.GXDLMSImageTransfer) error {
+	_ = r.media.Close()
+	var err error
+	for attempt := 0; attempt < max(r.RetryCount, 1); attempt++ {
+		if err = sleep(r.context(), imageActivateWaitTime); err != nil {
+			return err
+		}
+		if err = r.InitializeConnection(); err == nil {
+			break
+		}
+		_ = r.media.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("image activation not verified: %w", err)
+	}
+	if _, err = r.Read(target, 6); err != nil {
+		return fmt.Errorf("image activation not verified: %w", err)
+	}
+	if target.ImageTransferStatus != enums.ImageTransferStatusActivationSuccessful {
+		return fmt.Errorf("image activation failed: image transfer status is %s", target.ImageTransferStatus.String())
+	}
+	return nil
+}
+
+// EnterToken enters a prepayment token to the token gateway and waits until the meter has processed it.
+func (r *GXDLMSReader) EnterToken(target *objects.GXDLMSTokenGateway, token []byte, timeout time.Duration) error {
+	if target == nil || len(token) == 0 {
+		return gxcommon.ErrInvalidArgument
+	}
+	//Token is given as a parameter of the enter method.
+	if err := r.Method(target, 1, token); err != nil {
+		return err
+	}
+	r.logger.Debug("token entered, waiting for the result")
+	start := time.Now()
+	for {
+		//Meter processes the token asynchronously. Poll the token status until it's ready.
+		if _, err := r.Read(target, 6); err != nil {
+			return err
+		}
+		switch target.StatusCode {
+		case enums.TokenStatusCodeTokenExecutionOk:
+			return nil
+		case enums.TokenStatusCodeTokenFormatFailure,
+			enums.TokenStatusCodeAuthenticationFailure,
+			enums.TokenStatusCodeValidationResultFailure,
+			enums.TokenStatusCodeTokenExecutionResultFailure:
+			return fmt.Errorf("token rejected: %s", target.StatusCode.String())
+		}
+		if time.Since(start) > timeout {
+			return fmt.Errorf("token result not received in %s. Last status: %s", timeout, target.StatusCode.String())
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// ReadDemandRegister reads demand register values and the period attributes.
+func (r *GXDLMSReader) ReadDemandRegister(target *objects.GXDLMSDemandRegister) error {
+	if target == nil {
+		return gxcommon.ErrInvalidArgument
+	}
+	//Scaler and unit are read first so values are shown correctly.
+	for _, idx := range []int{4, 2, 3, 5, 6, 7, 8, 9} {
+		if !r.client.CanRead(target, idx) {
+			continue
+		}
+		if _, err := r.Read(target, idx); err != nil {
+			return fmt.Errorf("read %s:%d failed: %w", target.Base().LogicalName(), idx, err)
+		}
+	}
+	return nil
+}
+
+// GXDemandReset is the reset state of the demand register.
+type GXDemandReset struct {
+	// LastReset is the time stamp of the most recent billing period reset. It's zero if it's not known.
+	LastReset time.Time
+	// Schedule is the execution time of the end of billing period schedule. It's empty if the schedule is not configured.
+	Schedule string
+}
+
+// ReadDemandReset reads the time of the last reset and the linked reset schedule from the association view.
+// Demand register doesn't tell when it was reset, so the time stamp of the most recent billing period (0.0.0.1.2.255)
+// is used. Reset schedule is the end of billing period single action schedule (0.0.15.0.0.255).
+func (r *GXDLMSReader) ReadDemandReset() (GXDemandReset, error) {
+	var ret GXDemandReset
+	if obj := r.client.Objects().FindByLN(enums.ObjectTypeData, "0.0.0.1.2.255"); obj != nil && r.client.CanRead(obj, 2) {
+		v, err := r.Read(obj, 2)
+		if err != nil {
+			return ret, fmt.Errorf("read %s:2 failed: %w", obj.Base().LogicalName(), err)
+		}
+		switch v := v.(type) {
+		case types.GXDateTime:
+			ret.LastReset = v.Value
+		case *types.GXDateTime:
+			if v != nil {
+				ret.LastReset = v.Value
 			}
-			_, err = r.ReadDataBlocks(frames, reply)
-			if err == nil {
-				break
+		}
+	}
+	if obj := r.client.Objects().FindByLN(enums.ObjectTypeActionSchedule, "0.0.15.0.0.255"); obj != nil && r.client.CanRead(obj, 4) {
+		v, err := r.Read(obj, 4)
+		if err != nil {
+			return ret, fmt.Errorf("read %s:4 failed: %w", obj.Base().LogicalName(), err)
+		}
+		ret.Schedule = r.formatValue(v)
+	}
+	return ret, nil
+}
+
+// RemoteDisconnect invokes remote_disconnect method of the disconnect control and reads back
+// the output and control state.
+func (r *GXDLMSReader) RemoteDisconnect(target *objects.GXDLMSDisconnectControl) error {
+	return r.remoteControl(target, 1)
+}
+
+// RemoteReconnect invokes remote_reconnect method of the disconnect control and reads back
+// the output and control state.
+func (r *GXDLMSReader) RemoteReconnect(target *objects.GXDLMSDisconnectControl) error {
+	return r.remoteControl(target, 2)
+}
+
+// remoteControl invokes the disconnect control method and reads the output and control state.
+func (r *GXDLMSReader) remoteControl(target *objects.GXDLMSDisconnectControl, methodIndex int) error {
+	if target == nil {
+		return gxcommon.ErrInvalidArgument
+	}
+	if err := r.Method(target, methodIndex, int8(0)); err != nil {
+		return err
+	}
+	for _, idx := range []int{2, 3} {
+		if _, err := r.Read(target, idx); err != nil {
+			return fmt.Errorf("read %s:%d failed: %w", target.Base().LogicalName(), idx, err)
+		}
+	}
+	return nil
+}
+
+// GXTuneResult is the throughput of one HDLC frame and window size trial.
+type GXTuneResult struct {
+	FrameSize   uint16
+	WindowSize  uint8
+	Bytes       uint64
+	Duration    time.Duration
+	BytesPerSec float64
+	Err         error
+}
+
+// AutoTune reads the association view with different HDLC frame and window sizes and
+// returns the results. The connection is re-established between the trials.
+func (r *GXDLMSReader) AutoTune(frameSizes []uint16, windowSizes []uint8) ([]GXTuneResult, error) {
+	if r.client.InterfaceType() != enums.InterfaceTypeHDLC &&
+		r.client.InterfaceType() != enums.InterfaceTypeHdlcWithModeE {
+		return nil, errors.New("auto tune is available only for HDLC interface")
+	}
+	var results []GXTuneResult
+	for _, frameSize := range frameSizes {
+		for _, windowSize := range windowSizes {
+			res := GXTuneResult{FrameSize: frameSize, WindowSize: windowSize}
+			res.Err = r.tuneTrial(&res)
+			if res.Err != nil {
+				r.logger.Debug("auto tune failed", "frameSize", frameSize, "windowSize", windowSize, "error", res.Err)
+			} else {
+				r.logger.Debug("auto tune", "frameSize", frameSize, "windowSize", windowSize, "bytesPerSec", int(res.BytesPerSec))
 			}
-			if !errors.Is(err, enums.ErrorCodeTemporaryFailure) {
-				return err
+			results = append(results, res)
+		}
+	}
+	return results, nil
+}
+
+// tuneTrial re-negotiates HDLC settings and measures the time to read the association view.
+func (r *GXDLMSReader) tuneTrial(res *GXTuneResult) error {
+	if r.client.ConnectionState() != enums.ConnectionStateNone {
+		if err := r.Disconnect(); err != nil {
+			return err
+		}
+	}
+	hdlc := r.client.HdlcSettings()
+	if err := hdlc.SetMaxInfoRX(res.FrameSize); err != nil {
+		return err
+	}
+	if err := hdlc.SetMaxInfoTX(res.FrameSize); err != nil {
+		return err
+	}
+	if err := hdlc.SetWindowSizeRX(res.WindowSize); err != nil {
+		return err
+	}
+	if err := hdlc.SetWindowSizeTX(res.WindowSize); err != nil {
+		return err
+	}
+	if err := r.SNRMRequest(); err != nil {
+		return err
+	}
+	//Meter might not accept the proposed values.
+	res.FrameSize = hdlc.MaxInfoRX()
+	res.WindowSize = hdlc.WindowSizeRX()
+	if err := r.AarqRequest(); err != nil {
+		return err
+	}
+	frames, err := r.client.GetObjectsRequest()
+	if err != nil {
+		return err
+	}
+	received := r.media.GetBytesReceived()
+	start := time.Now()
+	if _, err = r.ReadDataBlocks(frames, dlms.NewGXReplyData()); err != nil {
+		return err
+	}
+	res.Duration = time.Since(start)
+	res.Bytes = r.media.GetBytesReceived() - received
+	if res.Duration > 0 {
+		res.BytesPerSec = float64(res.Bytes) / res.Duration.Seconds()
+	}
+	return nil
+}
+
+// GetMaximumDemands reads maximum demand registers (OBIS value group D is 6) with the capture time.
+// Registers of all billing periods are returned in logical name order.
+func (r *GXDLMSReader) GetMaximumDemands() ([]objects.IGXDLMSBase, error) {
+	var ret []objects.IGXDLMSBase
+	for _, it := range r.client.Objects().GetObjects2([]enums.ObjectType{
+		enums.ObjectTypeRegister,
+		enums.ObjectTypeExtendedRegister,
+	}) {
+		parts := strings.Split(it.Base().LogicalName(), ".")
+		if len(parts) != 6 || parts[3] != "6" {
+			continue
+		}
+		for _, idx := range []int{3, 2, 5} {
+			if idx == 5 && it.Base().ObjectType() != enums.ObjectTypeExtendedRegister {
+				continue
+			}
+			if !r.client.CanRead(it, idx) {
+				continue
+			}
+			if _, err := r.Read(it, idx); err != nil {
+				return nil, fmt.Errorf("read %s:%d failed: %w", it.Base().LogicalName(), idx, err)
 			}
-			r.writeTrace("Image activate temporary failed, retrying...")
-			time.Sleep(5 * time.Second)
 		}
-	*/
-	return err
+		ret = append(ret, it)
+	}
+	slices.SortFunc(ret, func(a, b objects.IGXDLMSBase) int {
+		return compareLN(a.Base().LogicalName(), b.Base().LogicalName())
+	})
+	return ret, nil
 }
 
 // GetAssociationView reads association view from the meter or from cache file.
+// If BestEffort is set and the association view is read only partially, the parsed objects are used
+// and the cache file is not saved.
 func (r *GXDLMSReader) GetAssociationView(outputFile string) (bool, error) {
-	if outputFile != "" {
+	r.partialView = false
+	var cached []objects.IGXDLMSBase
+	if outputFile != "" && !r.RefreshCache {
 		if _, err := os.Stat(outputFile); err == nil {
 			r.client.Objects().Clear()
 			if err = r.client.Objects().LoadFromFile(outputFile); err == nil && len(*r.client.Objects()) != 0 {
-				return false, nil
+				if r.cacheMatches(outputFile) {
+					return false, nil
+				}
+				//Cache is from the other meter or the object set has changed.
+				r.logger.Info("association view cache doesn't match the meter, reading it again", "file", outputFile)
 			}
+			cached = slices.Clone(*r.client.Objects())
+			r.client.Objects().Clear()
+			r.deviceName = nil
 			if err != nil {
+				r.logger.Warn("association view cache is corrupted, reading it again", "file", outputFile, "error", err)
 				_ = os.Remove(outputFile)
 			}
 		}
@@ -344,10 +795,26 @@ func (r *GXDLMSReader) GetAssociationView(outputFile string) (bool, error) {
 	}
 	reply := dlms.NewGXReplyData()
 	if _, err = r.ReadDataBlocks(frames, reply); err != nil {
-		return false, err
+		if !r.BestEffort || reply.Data == nil || reply.Data.Size() == 0 {
+			return false, err
+		}
+		r.logger.Warn("association view read failed, using the received objects", "error", err)
+		r.partialView = true
 	}
+	expected := associationViewCount(reply.Data.Array())
 	if _, err = r.client.ParseObjects(reply.Data, true); err != nil {
-		return false, err
+		if !r.BestEffort || len(*r.client.Objects()) == 0 {
+			return false, err
+		}
+		r.logger.Warn("association view parsing failed, using the parsed objects", "error", err)
+		r.partialView = true
+	}
+	//Cache is refreshed only once.
+	r.RefreshCache = false
+	r.viewCount = expected
+	if r.partialView {
+		r.logMissingObjects(expected, cached)
+		return true, nil
 	}
 
 	if !r.client.UseLogicalNameReferencing() {
@@ -359,9 +826,9 @@ func (r *GXDLMSReader) GetAssociationView(outputFile string) (bool, error) {
 	}
 
 	if outputFile != "" {
-		ret := r.client.Objects().SaveToFile(outputFile, &objects.GXXmlWriterSettings{Values: false})
-		if ret != nil {
-			return false, err
+		if err = r.saveCache(outputFile, &objects.GXXmlWriterSettings{Values: false}); err != nil {
+			//Objects are read from the device even if the cache can't be saved.
+			return true, fmt.Errorf("failed to save association view to %s: %w", outputFile, err)
 		}
 	}
 	return true, nil
@@ -375,6 +842,9 @@ func (r *GXDLMSReader) GetScalersAndUnits() {
 		enums.ObjectTypeDemandRegister,
 	})
 	for _, it := range objs {
+		if !r.readsObjectType(it.Base().ObjectType()) {
+			continue
+		}
 		idx := 3
 		if it.Base().ObjectType() == enums.ObjectTypeDemandRegister {
 			idx = 4
@@ -382,17 +852,20 @@ func (r *GXDLMSReader) GetScalersAndUnits() {
 		if !r.client.CanRead(it, idx) {
 			continue
 		}
-		if _, err := r.Read(it, idx); err != nil && r.trace > gxcommon.TraceLevelWarning {
-			r.writeTrace(fmt.Sprintf("Failed reading scaler/unit %s:%d: %v", it.Base().LogicalName(), idx, err))
+		if _, err := r.Read(it, idx); err != nil {
+			r.logger.Debug("failed reading scaler/unit", "ln", it.Base().LogicalName(), "index", idx, "error", err)
 		}
 	}
 }
 
 // GetProfileGenericColumns reads profile generic capture object metadata.
 func (r *GXDLMSReader) GetProfileGenericColumns() {
+	if !r.readsObjectType(enums.ObjectTypeProfileGeneric) {
+		return
+	}
 	for _, it := range r.client.Objects().GetObjects(enums.ObjectTypeProfileGeneric) {
-		if _, err := r.Read(it, 3); err != nil && r.trace > gxcommon.TraceLevelWarning {
-			r.writeTrace(fmt.Sprintf("Failed reading profile columns %s: %v", it.Base().LogicalName(), err))
+		if _, err := r.Read(it, 3); err != nil {
+			r.logger.Debug("failed reading profile columns", "ln", it.Base().LogicalName(), "error", err)
 		}
 	}
 }
@@ -402,94 +875,112 @@ func (r *GXDLMSReader) showValue(val any, pos int) string {
 	if r.trace <= gxcommon.TraceLevelWarning {
 		return ""
 	}
+	formatted := r.formatValue(val)
+	if pos != 0 {
+		r.logger.Debug("value", "index", pos, "value", formatted)
+	}
+	return formatted
+}
+
+// formatValue converts read attribute value to the string.
+func (r *GXDLMSReader) formatValue(val any) string {
 	var formatted string
 	if v, ok := val.([]byte); ok {
 		formatted = types.ToHex(v, true)
 	} else if v, ok := val.(types.GXDateTime); ok {
-		formatted = v.String()
+		formatted = r.formatDateTime(&v)
+	} else if v, ok := val.(*types.GXDateTime); ok && v != nil {
+		formatted = r.formatDateTime(v)
 	} else if v, ok := val.(types.GXDate); ok {
 		formatted = v.String()
 	} else if v, ok := val.(types.GXTime); ok {
 		formatted = v.String()
-	} else if v, ok := val.(types.GXTime); ok {
-		formatted = v.String()
-	} else if arr, ok := val.(types.GXArray); ok {
-		parts := make([]string, 0, len(arr))
-		for _, item := range arr {
-			parts = append(parts, r.showValue(item, 0))
-		}
-		formatted = "[" + strings.Join(parts, ", ") + "]"
 	} else if arr, ok := val.(types.GXArray); ok {
 		parts := make([]string, 0, len(arr))
 		for _, item := range arr {
-			parts = append(parts, r.showValue(item, 0))
-		}
-		formatted = "[" + strings.Join(parts, ", ") + "]"
-	} else if arr, ok := val.(types.GXArray); ok {
-		parts := make([]string, 0, len(arr))
-		for _, item := range arr {
-			parts = append(parts, r.showValue(item, 0))
+			parts = append(parts, r.formatValue(item))
 		}
 		formatted = "[" + strings.Join(parts, ", ") + "]"
 	} else if arr, ok := val.([]any); ok {
 		parts := make([]string, 0, len(arr))
 		for _, item := range arr {
-			parts = append(parts, r.showValue(item, 0))
+			parts = append(parts, r.formatValue(item))
 		}
 		formatted = "[" + strings.Join(parts, ", ") + "]"
 	} else if arr, ok := val.([][]any); ok {
 		parts := make([]string, 0, len(arr))
 		for _, item := range arr {
-			parts = append(parts, r.showValue(item, 0))
+			parts = append(parts, r.formatValue(item))
 		}
 		formatted = "{" + strings.Join(parts, ", ") + "}"
 	} else {
 		formatted = fmt.Sprint(val)
 	}
-	if pos != 0 {
-		r.writeTrace(fmt.Sprintf("Index: %d Value: %s", pos, formatted))
-	}
 	return formatted
 }
 
 // GetProfileGenerics reads profile generic rows.
 func (r *GXDLMSReader) GetProfileGenerics() {
+	if !r.readsObjectType(enums.ObjectTypeProfileGeneric) {
+		return
+	}
 	//Find profile generics objects and read them.
 	for _, it := range r.client.Objects().GetObjects(enums.ObjectTypeProfileGeneric) {
-		pg, ok := it.(*objects.GXDLMSProfileGeneric)
-		if !ok {
-			continue
-		}
-		if r.client.CanRead(pg, 7) {
-			_, _ = r.Read(pg, 7)
-		}
-		if r.client.CanRead(pg, 8) {
-			_, _ = r.Read(pg, 8)
-		}
-		//If there are no columns or rows.
-		if len(pg.CaptureObjects) == 0 || pg.EntriesInUse == 0 {
-			continue
-		}
-		if rows, err := r.ReadRowsByEntry(pg, 1, 1); err == nil && r.trace > gxcommon.TraceLevelWarning {
-			r.writeTrace(fmt.Sprintf("Profile %s first row:", pg.Base().LogicalName()))
-			r.showValue(rows, 2)
-		}
-		//Read last day from Profile Generic.
-		now := time.Now()
-		midnight := time.Date(
-			now.Year(),
-			now.Month(),
-			now.Day(),
-			0, 0, 0, 0,
-			now.Location(),
-		)
-		s := *types.NewGXDateTimeFromTime(midnight)
-		midnight = midnight.Add(24 * time.Hour)
-		e := *types.NewGXDateTimeFromTime(midnight)
-		if rows, err := r.ReadRowsByRange(pg, s, e); err == nil && r.trace > gxcommon.TraceLevelWarning {
-			r.writeTrace(fmt.Sprintf("Profile %s last day:", pg.Base().LogicalName()))
-			r.showValue(rows, 2)
+		if pg, ok := it.(*objects.GXDLMSProfileGeneric); ok {
+			r.readProfileGeneric(pg)
 		}
+		r.stepProgress()
+	}
+}
+
+// readProfileGeneric reads the first row and the rows of the current day.
+func (r *GXDLMSReader) readProfileGeneric(pg *objects.GXDLMSProfileGeneric) {
+	if r.client.CanRead(pg, 7) {
+		_, _ = r.Read(pg, 7)
+	}
+	if r.client.CanRead(pg, 8) {
+		_, _ = r.Read(pg, 8)
+	}
+	//If there are no columns or rows.
+	if len(pg.CaptureObjects) == 0 || pg.EntriesInUse == 0 {
+		return
+	}
+	//Scalers and units of the captured registers are needed to show the rows.
+	//Read that failed because the connection was lost is read again with the new association like in GetReadOut.
+	if _, err := r.ReadProfileColumns(pg); err != nil && r.reconnect(err) {
+		_, _ = r.ReadProfileColumns(pg)
+	}
+	rows, err := r.ReadRowsByEntry(pg, 1, 1)
+	if err != nil && r.reconnect(err) {
+		rows, err = r.ReadRowsByEntry(pg, 1, 1)
+	}
+	if err != nil {
+		r.addFailure(pg, 2, err)
+	} else {
+		r.logger.Debug("profile first row", "ln", pg.Base().LogicalName())
+		r.showValue(rows, 2)
+	}
+	//Read last day from Profile Generic.
+	now := time.Now()
+	midnight := time.Date(
+		now.Year(),
+		now.Month(),
+		now.Day(),
+		0, 0, 0, 0,
+		now.Location(),
+	)
+	s := *types.NewGXDateTimeFromTime(midnight)
+	midnight = midnight.Add(24 * time.Hour)
+	e := *types.NewGXDateTimeFromTime(midnight)
+	rows, err = r.ReadRowsByRange(pg, s, e)
+	if err != nil && r.reconnect(err) {
+		rows, err = r.ReadRowsByRange(pg, s, e)
+	}
+	if err != nil {
+		r.addFailure(pg, 2, err)
+	} else {
+		r.logger.Debug("profile last day", "ln", pg.Base().LogicalName())
+		r.showValue(rows, 2)
 	}
 }
 
@@ -504,38 +995,340 @@ func (r *GXDLMSReader) GetCompactData() {
 	}
 }
 
+// objectsToRead returns the objects that are read. If Limit is set, only the first objects
+// in logical name order are returned.
+func (r *GXDLMSReader) objectsToRead() []objects.IGXDLMSBase {
+	objs := []objects.IGXDLMSBase(*r.client.Objects())
+	if len(r.ObjectTypes) != 0 {
+		objs = r.client.Objects().GetObjects2(r.ObjectTypes)
+	}
+	if r.Limit <= 0 || len(objs) <= r.Limit {
+		return objs
+	}
+	sorted := slices.Clone(objs)
+	slices.SortStableFunc(sorted, func(a, b objects.IGXDLMSBase) int {
+		return compareLN(a.Base().LogicalName(), b.Base().LogicalName())
+	})
+	r.logger.Info("read is truncated", "limit", r.Limit, "objects", len(objs))
+	return sorted[:r.Limit]
+}
+
+// readsObjectType returns true if the objects of the given type are read.
+func (r *GXDLMSReader) readsObjectType(objectType enums.ObjectType) bool {
+	return len(r.ObjectTypes) == 0 || slices.Contains(r.ObjectTypes, objectType)
+}
+
+// compareLN compares logical names numerically.
+func compareLN(a, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		va, _ := strconv.Atoi(pa[i])
+		vb, _ := strconv.Atoi(pb[i])
+		if va != vb {
+			return va - vb
+		}
+	}
+	return len(pa) - len(pb)
+}
+
 // GetReadOut reads all readable attributes except profile generic data rows.
-func (r *GXDLMSReader) GetReadOut() {
-	for _, it := range *r.client.Objects() {
+// Error is returned if the connection is lost. Attributes that were not read are added to the failures.
+func (r *GXDLMSReader) GetReadOut() error {
+	objs := r.objectsToRead()
+	r.addProgressTotal(objs)
+	for n, it := range objs {
 		if it.Base().ObjectType() == enums.ObjectTypeProfileGeneric {
 			continue
 		}
-		for _, pos := range it.GetAttributeIndexToRead(true) {
+		indexes := attributeReadOrder(it)
+		for i, pos := range indexes {
 			if !r.client.CanRead(it, pos) {
 				continue
 			}
 			val, err := r.Read(it, pos)
+			if err != nil && r.reconnect(err) {
+				//Attribute that failed is read again with the new association.
+				val, err = r.Read(it, pos)
+			}
 			if err != nil {
-				if r.trace > gxcommon.TraceLevelError {
-					r.writeTrace(fmt.Sprintf("Read failed %s:%d: %v", it.Base().LogicalName(), pos, err))
+				r.addFailure(it, pos, err)
+				var dlmsErr *GXDLMSError
+				if errors.As(err, &dlmsErr) && dlmsErr.Permanent() {
+					//Access is denied or the attribute is not available. Next attribute is read.
+					r.logger.Debug("attribute not read", "ln", it.Base().LogicalName(), "index", pos, "error", err)
+					continue
+				}
+				r.logger.Warn("read failed", "ln", it.Base().LogicalName(), "index", pos, "error", err)
+				//Meter doesn't answer and the rest of the objects would only wait for the timeouts.
+				if isLinkError(err) || r.context().Err() != nil {
+					r.addUnread(it, indexes[i+1:], objs[n+1:], err)
+					return fmt.Errorf("read-out stopped at %s:%d: %w", it.Base().LogicalName(), pos, err)
 				}
 				continue
 			}
-			r.showValue(val, pos)
+			r.showValue(r.displayValue(it, pos, val), pos)
+		}
+		r.stepProgress()
+	}
+	return nil
+}
+
+// addUnread adds the readable attributes that were not read because the read-out was stopped to the failures.
+func (r *GXDLMSReader) addUnread(obj objects.IGXDLMSBase, indexes []int, objs []objects.IGXDLMSBase, err error) {
+	for _, pos := range indexes {
+		if r.client.CanRead(obj, pos) {
+			r.addFailure(obj, pos, err)
+		}
+	}
+	for _, it := range objs {
+		if it.Base().ObjectType() == enums.ObjectTypeProfileGeneric {
+			continue
+		}
+		for _, pos := range attributeReadOrder(it) {
+			if r.client.CanRead(it, pos) {
+				r.addFailure(it, pos, err)
+			}
 		}
 	}
 }
 
+// reconnect re-establishes the association if err is caused by the lost connection and
+// reconnects are left. True is returned if the reading can continue with the new association.
+func (r *GXDLMSReader) reconnect(err error) bool {
+	if r.reconnects >= r.ReconnectCount || !isLinkError(err) {
+		return false
+	}
+	r.reconnects++
+	r.logger.Warn("connection lost, reconnecting", "attempt", r.reconnects, "error", err)
+	_ = r.media.Close()
+	if err = r.InitializeConnection(); err != nil {
+		r.logger.Warn("reconnect failed", "error", err)
+		return false
+	}
+	return true
+}
+
+// GXReadFailure is one attribute that ReadAll failed to read.
+type GXReadFailure struct {
+	LogicalName string
+	ObjectType  enums.ObjectType
+	Index       int
+	Err         error
+}
+
+// addFailure remembers the failed attribute read for the post-run report.
+func (r *GXDLMSReader) addFailure(obj objects.IGXDLMSBase, index int, err error) {
+	r.failures = append(r.failures, GXReadFailure{
+		LogicalName: obj.Base().LogicalName(),
+		ObjectType:  obj.Base().ObjectType(),
+		Index:       index,
+		Err:         err,
+	})
+}
+
+// Failures returns the attributes that the last ReadAll failed to read.
+func (r *GXDLMSReader) Failures() []GXReadFailure {
+	return r.failures
+}
+
+// GXReadResult is the result of one attribute read.
+type GXReadResult struct {
+	Index int
+	Value any
+	Err   error
+}
+
+// ReadCursor returns an iterator that reads the objects of the given type one object at a time.
+// Object is read only when the caller advances the iterator. ObjectTypeNone reads all objects.
+func (r *GXDLMSReader) ReadCursor(objectType enums.ObjectType) iter.Seq2[objects.IGXDLMSBase, []GXReadResult] {
+	return func(yield func(objects.IGXDLMSBase, []GXReadResult) bool) {
+		var objs []objects.IGXDLMSBase
+		if objectType == enums.ObjectTypeNone {
+			objs = *r.client.Objects()
+		} else {
+			objs = r.client.Objects().GetObjects(objectType)
+		}
+		for _, it := range objs {
+			var results []GXReadResult
+			for _, pos := range it.GetAttributeIndexToRead(true) {
+				if !r.client.CanRead(it, pos) {
+					continue
+				}
+				val, err := r.Read(it, pos)
+				results = append(results, GXReadResult{Index: pos, Value: val, Err: err})
+			}
+			if !yield(it, results) {
+				return
+			}
+		}
+	}
+}
+
+// updateFrameCounter reads the invocation counter using the public client and updates it to the ciphering
+// settings. Many meters reject ciphered AARQ if the invocation counter is not synchronized.
 func (r *GXDLMSReader) updateFrameCounter() error {
-	// Invocation counter update logic can be added here if meter requires it.
+	if r.InvocationCounter == "" || r.client.Ciphering().Security() == enums.SecurityNone {
+		return nil
+	}
+	if err := r.readFrameCounter(); err != nil {
+		return fmt.Errorf("invocation counter update failed: %w", err)
+	}
 	return nil
 }
 
+// readFrameCounter reads the invocation counter with the public client.
+func (r *GXDLMSReader) readFrameCounter() error {
+	value, err := r.readPublicData(r.InvocationCounter)
+	if err != nil {
+		return err
+	}
+	ic, ok := toFloat(value)
+	if !ok {
+		return fmt.Errorf("invalid invocation counter value %v", value)
+	}
+	r.logger.Debug("invocation counter", "value", uint32(ic))
+	return r.client.Ciphering().SetInvocationCounter(uint32(ic) + 1)
+}
+
+// readPublicData opens a public client association next to the ciphered association and reads the value of the data object.
+// The ciphered association is not closed if it's already open.
+func (r *GXDLMSReader) readPublicData(ln string) (any, error) {
+	public, err := r.openAuxiliaryAssociation(16, enums.AuthenticationNone)
+	if err != nil {
+		return nil, err
+	}
+	d, err := objects.NewGXDLMSData(ln, 0)
+	if err != nil {
+		_ = public.Disconnect()
+		return nil, err
+	}
+	value, err := public.Read(d, 2)
+	if err != nil {
+		_ = public.Disconnect()
+		return nil, err
+	}
+	if err = public.Disconnect(); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// openAuxiliaryAssociation opens a second association with the given client address. It uses the same media
+// and the primary association stays open, so meters that allow several clients at the same time are not
+// associated again. Low authentication uses the password of the primary client. HDLC, invoke ID
+// and retry settings are copied from the primary reader.
+// Call Disconnect of the returned reader to close the association. Media is not closed.
+func (r *GXDLMSReader) openAuxiliaryAssociation(clientAddress int, authentication enums.Authentication) (*GXDLMSReader, error) {
+	var password []byte
+	if authentication == enums.AuthenticationLow {
+		password = r.client.Password()
+	}
+	client, err := dlms.NewGXDLMSSecureClient(r.client.UseLogicalNameReferencing(), clientAddress,
+		r.client.ServerAddress(), authentication, password, r.client.InterfaceType())
+	if err != nil {
+		return nil, err
+	}
+	if err = client.SetStandard(r.client.Standard()); err != nil {
+		return nil, err
+	}
+	if gw := r.client.Gateway(); gw != nil {
+		if err = client.SetGateway(gw); err != nil {
+			return nil, err
+		}
+	}
+	//Link and invoke ID settings are the same as in the primary association.
+	if err = copyHdlcSettings(client.HdlcSettings(), r.client.HdlcSettings()); err != nil {
+		return nil, err
+	}
+	if err = client.SetServiceClass(r.client.ServiceClass()); err != nil {
+		return nil, err
+	}
+	if err = client.SetAutoIncreaseInvokeID(r.client.AutoIncreaseInvokeID()); err != nil {
+		return nil, err
+	}
+	aux := NewGXDLMSReader(client, r.media, r.trace, "", r.WaitTime, r.logger.With("clientAddress", clientAddress))
+	aux.Context = r.Context
+	aux.RetryCount = r.RetryCount
+	aux.TempRetryCount = r.TempRetryCount
+	aux.TempWaitTime = r.TempWaitTime
+	aux.Jitter = r.Jitter
+	aux.BackoffBase = r.BackoffBase
+	aux.BackoffMax = r.BackoffMax
+	aux.BackoffMultiplier = r.BackoffMultiplier
+	aux.RxChunk = r.RxChunk
+	aux.HexDumpFile = r.HexDumpFile
+	aux.Stats = r.Stats
+	if err = aux.SNRMRequest(); err != nil {
+		return nil, err
+	}
+	if err = aux.AarqRequest(); err != nil {
+		_ = aux.Disconnect()
+		return nil, err
+	}
+	return aux, nil
+}
+
+// copyHdlcSettings copies the maximum frame sizes and window sizes (-f and -w).
+func copyHdlcSettings(target, source *settings.GXHdlcSettings) error {
+	if err := target.SetMaxInfoRX(source.MaxInfoRX()); err != nil {
+		return err
+	}
+	if err := target.SetMaxInfoTX(source.MaxInfoTX()); err != nil {
+		return err
+	}
+	if err := target.SetWindowSizeRX(source.WindowSizeRX()); err != nil {
+		return err
+	}
+	return target.SetWindowSizeTX(source.WindowSizeTX())
+}
+
+// Identify reads the logical device name with the public client before the association.
+// The first three characters of the name are the manufacturer flag ID. The manufacturer ID
+// of the client is set from it if it's not given.
+func (r *GXDLMSReader) Identify() (string, error) {
+	if !r.media.IsOpen() {
+		if err := openMedia(r.media); err != nil {
+			return "", err
+		}
+	}
+	if err := r.initializeOpticalHead(); err != nil {
+		return "", err
+	}
+	value, err := r.readPublicData("0.0.42.0.0.255")
+	if err != nil {
+		return "", err
+	}
+	var name string
+	switch v := value.(type) {
+	case []byte:
+		name = string(v)
+	case string:
+		name = v
+	default:
+		return "", fmt.Errorf("invalid logical device name %v", value)
+	}
+	if len(name) >= 3 && r.client.ManufacturerID() == "" {
+		if err = r.client.SetManufacturerID(name[:3]); err != nil {
+			return name, err
+		}
+	}
+	return name, nil
+}
+
 // ReadAll performs complete read sequence and saves objects to file if outputFile is not empty.
 func (r *GXDLMSReader) ReadAll(outputFile string) error {
 	if err := r.InitializeConnection(); err != nil {
 		return err
 	}
+	return r.readAll(outputFile)
+}
+
+// readAll reads all objects using the established connection.
+func (r *GXDLMSReader) readAll(outputFile string) error {
+	//Reconnects are counted for each read because the reader is reused in the interval mode.
+	r.failures = nil
+	r.reconnects = 0
 	readFromDevice, err := r.GetAssociationView(outputFile)
 	if err != nil {
 		return err
@@ -545,10 +1338,33 @@ func (r *GXDLMSReader) ReadAll(outputFile string) error {
 		r.GetProfileGenericColumns()
 	}
 	r.GetCompactData()
-	r.GetReadOut()
-	r.GetProfileGenerics()
-	if outputFile != "" {
-		_ = r.client.Objects().SaveToFile(outputFile, &objects.GXXmlWriterSettings{
+	if r.OnProgress != nil {
+		profileGenerics := 0
+		if !r.SkipProfiles && r.readsObjectType(enums.ObjectTypeProfileGeneric) {
+			profileGenerics = len(r.client.Objects().GetObjects(enums.ObjectTypeProfileGeneric))
+		}
+		r.startProgress(profileGenerics)
+		defer r.stopProgress()
+	}
+	if !r.SkipReadOut {
+		//Profile generics are not read if the connection is lost.
+		if err := r.GetReadOut(); err != nil {
+			if !r.SkipProfiles && r.readsObjectType(enums.ObjectTypeProfileGeneric) {
+				for _, it := range r.client.Objects().GetObjects(enums.ObjectTypeProfileGeneric) {
+					if r.client.CanRead(it, 2) {
+						r.addFailure(it, 2, err)
+					}
+				}
+			}
+			return err
+		}
+	}
+	if !r.SkipProfiles {
+		r.GetProfileGenerics()
+	}
+	//Incomplete association view is not saved or it would be used on the next run.
+	if outputFile != "" && !r.partialView {
+		_ = r.saveCache(outputFile, &objects.GXXmlWriterSettings{
 			UseMeterTime:        true,
 			IgnoreDefaultValues: false,
 		})
@@ -566,18 +1382,56 @@ func (r *GXDLMSReader) SNRMRequest() error {
 	if frame == nil {
 		return nil
 	}
+	//UA replaces the requested values with the values that the meter accepts. ParseUAResponse stores
+	//the negotiated values to HdlcSettings and the library uses them when the frames are built.
+	hdlc := r.client.HdlcSettings()
+	requested := gxHdlcParameters{hdlc.MaxInfoTX(), hdlc.MaxInfoRX(), hdlc.WindowSizeTX(), hdlc.WindowSizeRX()}
 	if err := r.ReadDataBlock(frame, reply); err != nil {
 		return err
 	}
-	if r.trace > gxcommon.TraceLevelInfo {
-		r.writeTrace("Parsing UA reply")
-	}
+	r.logger.Debug("parsing UA reply")
 	if err := r.client.ParseUAResponse(reply.Data); err != nil {
 		return err
 	}
+	r.logHdlcParameters(requested)
 	return nil
 }
 
+// PDUSize returns the max PDU size that the meter accepted and the size that was proposed in AARQ.
+func (r *GXDLMSReader) PDUSize() (uint16, uint16) {
+	return r.client.MaxReceivePDUSize(), r.requestedPDU
+}
+
+// gxHdlcParameters are the HDLC frame sizes and window sizes.
+type gxHdlcParameters struct {
+	MaxInfoTX    uint16
+	MaxInfoRX    uint16
+	WindowSizeTX uint8
+	WindowSizeRX uint8
+}
+
+// logHdlcParameters logs the requested and negotiated HDLC parameters.
+// Warning is logged if the meter accepts less than half of the requested frame size or window size
+// because it slows down the reading.
+func (r *GXDLMSReader) logHdlcParameters(requested gxHdlcParameters) {
+	hdlc := r.client.HdlcSettings()
+	negotiated := gxHdlcParameters{hdlc.MaxInfoTX(), hdlc.MaxInfoRX(), hdlc.WindowSizeTX(), hdlc.WindowSizeRX()}
+	args := []any{
+		"maxInfoTX", negotiated.MaxInfoTX, "requestedMaxInfoTX", requested.MaxInfoTX,
+		"maxInfoRX", negotiated.MaxInfoRX, "requestedMaxInfoRX", requested.MaxInfoRX,
+		"windowSizeTX", negotiated.WindowSizeTX, "requestedWindowSizeTX", requested.WindowSizeTX,
+		"windowSizeRX", negotiated.WindowSizeRX, "requestedWindowSizeRX", requested.WindowSizeRX,
+	}
+	if 2*int(negotiated.MaxInfoRX) < int(requested.MaxInfoRX) ||
+		2*int(negotiated.MaxInfoTX) < int(requested.MaxInfoTX) ||
+		2*int(negotiated.WindowSizeRX) < int(requested.WindowSizeRX) ||
+		2*int(negotiated.WindowSizeTX) < int(requested.WindowSizeTX) {
+		r.logger.Warn("meter limited HDLC frame or window size", args...)
+		return
+	}
+	r.logger.Debug("HDLC parameters", args...)
+}
+
 // AarqRequest sends AARQ and optional HLS application association.
 func (r *GXDLMSReader) AarqRequest() error {
 	reply := dlms.NewGXReplyData()
@@ -588,14 +1442,26 @@ func (r *GXDLMSReader) AarqRequest() error {
 	if len(frames) == 0 {
 		return nil
 	}
+	r.logGateway(frames[0])
 	for _, frame := range frames {
 		reply.Clear()
 		if err := r.ReadDataBlock(frame, reply); err != nil {
 			return err
 		}
 	}
+	//AARE replaces the proposed PDU size with the size that the meter can receive.
+	r.requestedPDU = r.client.MaxReceivePDUSize()
+	expected := slices.Clone(r.client.Ciphering().RecipientSystemTitle())
 	if err := r.client.ParseAAREResponse(reply.Data); err != nil {
-		return err
+		return &GXAssociationRejectedError{Err: err}
+	}
+	if r.StrictTitle && len(expected) != 0 && !bytes.Equal(expected, r.client.SourceSystemTitle()) {
+		return &GXSystemTitleError{Expected: expected, Actual: r.client.SourceSystemTitle()}
+	}
+	//Library builds the following requests with the negotiated size and splits them to blocks when needed.
+	//Image block size is the only size that the meter decides, so it's checked in CheckImageTransfer.
+	if r.client.MaxReceivePDUSize() < r.requestedPDU {
+		r.logger.Info("meter limited PDU size", "maxPduSize", r.client.MaxReceivePDUSize(), "requestedMaxPduSize", r.requestedPDU)
 	}
 	if r.client.Authentication() > enums.AuthenticationLow {
 		hls, err := r.client.GetApplicationAssociationRequest()
@@ -612,17 +1478,69 @@ func (r *GXDLMSReader) AarqRequest() error {
 			return err
 		}
 	}
-	return nil
+	return nil
+}
+
+// context returns the context of the reads that are made without the context.
+func (r *GXDLMSReader) context() context.Context {
+	if r.Context == nil {
+		return context.Background()
+	}
+	return r.Context
+}
+
+// ReadDLMSPacket sends one DLMS packet and waits until one complete response is parsed.
+// The packet is resent if the meter is busy and returns temporary failure or rejects the request.
+// GXDLMSError is returned if the meter replies with an error, e.g. access is denied.
+func (r *GXDLMSReader) ReadDLMSPacket(data []byte, reply *dlms.GXReplyData) error {
+	return r.ReadDLMSPacketContext(r.context(), data, reply)
+}
+
+// ReadDLMSPacketContext sends data to the meter and receives the reply.
+// Retrying is stopped when the context is cancelled.
+func (r *GXDLMSReader) ReadDLMSPacketContext(ctx context.Context, data []byte, reply *dlms.GXReplyData) error {
+	return r.retryBusy(ctx, data, func() error {
+		return r.readDLMSPacket(ctx, data, reply)
+	})
+}
+
+// retryBusy calls send again while the meter is busy. Delay between the retries grows like
+// the delay between the resends, but it starts from TempWaitTime.
+func (r *GXDLMSReader) retryBusy(ctx context.Context, data []byte, send func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := send()
+		retries := r.busyRetries(err)
+		if !isRetryable(err) || attempt >= retries {
+			return err
+		}
+		delay := r.backoff(r.TempWaitTime, attempt+1)
+		r.logger.Debug("meter is busy, retrying", "error", err, "delay", delay, "attempt", attempt+1, "retryCount", retries)
+		r.Stats.addRetry()
+		if err = sleep(ctx, delay); err != nil {
+			return frameError(data, err)
+		}
+	}
 }
 
-// ReadDLMSPacket sends one DLMS packet and waits until one complete response is parsed.
-func (r *GXDLMSReader) ReadDLMSPacket(data []byte, reply *dlms.GXReplyData) error {
+// busyRetries returns how many times the operation is retried after err. Rejected operation
+// is retried at least once, because the meter rejects the request when it's still handling the previous one.
+func (r *GXDLMSReader) busyRetries(err error) int {
+	if errors.Is(err, enums.ErrorCodeRejected) {
+		return max(r.TempRetryCount, 1)
+	}
+	return r.TempRetryCount
+}
+
+func (r *GXDLMSReader) readDLMSPacket(ctx context.Context, data []byte, reply *dlms.GXReplyData) error {
 	if reply == nil {
 		return errors.New("reply is nil")
 	}
 	if data == nil && !reply.IsStreaming() {
 		return nil
 	}
+	if err := ctx.Err(); err != nil {
+		return frameError(data, err)
+	}
 
 	notify := dlms.NewGXReplyData()
 	reply.Error = 0
@@ -637,51 +1555,70 @@ func (r *GXDLMSReader) ReadDLMSPacket(data []byte, reply *dlms.GXReplyData) erro
 
 	var err error
 	rd := types.NewGXByteBuffer()
+	//Bytes that were received after the previous reply, e.g. the next HDLC frame of the window.
+	if len(r.pending) != 0 {
+		if err = rd.Set(r.pending); err != nil {
+			return err
+		}
+		r.pending = nil
+	}
 	attempt := 0
 	succeeded := false
 	p := gxcommon.NewReceiveParameters[[]byte]()
 	p.EOP = eop
-	p.Count = r.client.GetFrameSize(rd)
+	p.Count = r.receiveCount(rd)
 	p.AllData = true
 	p.WaitTime = r.WaitTime
-	for !succeeded && attempt != 3 {
+	for !succeeded {
+		//Streamed replies are received without sending anything.
 		if !reply.IsStreaming() {
 			if len(data) == 0 {
 				return errors.New("packet is empty")
 			}
-			r.writeTrace("TX:\t" + time.Now().Format("15:04:05.000") + "\t" + types.ToHex(data, true))
+			r.logger.Debug("frame", "direction", "TX", "bytes", len(data), "data", types.ToHex(data, true))
+			r.recordFrame(true, data)
+			r.writeHexDump(true, data)
 			if err := r.media.Send(data, ""); err != nil {
 				return err
 			}
-			succeeded, err = r.media.Receive(p)
-			if err != nil {
+		}
+		if r.frameReady(rd) {
+			break
+		}
+		succeeded, err = r.receive(p, rd)
+		if err != nil {
+			return err
+		}
+		if succeeded {
+			if err = setReply(rd, p.Reply); err != nil {
 				return err
 			}
-			if !succeeded {
-				attempt++
-				if attempt >= r.RetryCount {
-					return errors.New("failed to receive reply from the device in given time")
-				}
-				//If EOP is not set read one byte at time.
-				if p.EOP == nil {
-					p.Count = 1
-				}
-				//Try to read again...
-				log.Printf("Data send failed. Try to resend %d/3\n", attempt)
+		} else {
+			attempt++
+			if attempt >= r.RetryCount {
+				return ErrReceiveTimeout
+			}
+			//If EOP is not set read one byte at time.
+			if p.EOP == nil {
+				p.Count = 1
+			}
+			//Try to read again...
+			r.logger.Warn("data send failed, resending", "attempt", attempt, "retryCount", r.RetryCount)
+			r.Stats.addRetry()
+			if err = sleep(ctx, r.jitter()); err != nil {
+				return frameError(data, err)
 			}
 		}
 	}
-
-	err = rd.Set(p.Reply.([]byte))
-	if err != nil {
-		return err
-	}
 	attempt = 0
 	//Loop until whole COSEM packet is received.
 	complete := false
 	for {
 		complete, err = r.client.GetData(rd, reply, notify)
 		if err != nil {
+			if isCipherError(err, r.client.Ciphering().Security()) {
+				return &GXCipherError{Err: err}
+			}
 			return err
 		}
 		if complete {
@@ -693,11 +1630,18 @@ func (r *GXDLMSReader) ReadDLMSPacket(data []byte, reply *dlms.GXReplyData) erro
 			}
 			notify.Clear()
 		}
+		if err = ctx.Err(); err != nil {
+			return frameError(data, err)
+		}
+		//Chunk may contain several frames.
+		if r.frameReady(rd) {
+			continue
+		}
 		if p.EOP == nil {
-			p.Count = r.client.GetFrameSize(rd)
+			p.Count = r.receiveCount(rd)
 		}
 		for {
-			succeeded, err = r.media.Receive(p)
+			succeeded, err = r.receive(p, rd)
 			if err != nil {
 				return err
 			}
@@ -706,39 +1650,162 @@ func (r *GXDLMSReader) ReadDLMSPacket(data []byte, reply *dlms.GXReplyData) erro
 			}
 			attempt++
 			if attempt >= r.RetryCount {
-				return errors.New("failed to receive reply from the device in given time")
+				return ErrReceiveTimeout
 			}
 			p.Reply = nil
+			if err = sleep(ctx, r.backoff(r.BackoffBase, attempt)); err != nil {
+				return frameError(data, err)
+			}
 			if err := r.media.Send(data, ""); err != nil {
 				return err
 			}
 			//Try to read again...
-			log.Printf("Data send failed. Try to resend %d/3\n", attempt)
+			r.logger.Warn("data send failed, resending", "attempt", attempt, "retryCount", r.RetryCount)
+			r.Stats.addRetry()
 		}
-		err = rd.Set(p.Reply.([]byte))
-		if err != nil {
+		if err = setReply(rd, p.Reply); err != nil {
 			return err
 		}
 	}
-	r.writeTrace("RX:\t" + time.Now().Format("15:04:05.000") + "\t" + rd.String())
+	r.logger.Debug("frame", "direction", "RX", "bytes", rd.Size(), "data", rd.String())
+	r.recordFrame(false, rd.Array())
+	r.writeHexDump(false, rd.Array())
+	//Rest of the chunk belongs to the next reply.
+	if rd.Available() != 0 {
+		r.pending = bytes.Clone(rd.Array()[rd.Position():])
+	}
 	if reply.Error != 0 {
-		if reply.Error == int(enums.ErrorCodeRejected) {
-			time.Sleep(time.Second)
-			return r.ReadDLMSPacket(data, reply)
-		}
-		return enums.ErrorCode(reply.Error)
+		return &GXDLMSError{Code: enums.ErrorCode(reply.Error)}
 	}
 	return nil
 }
 
+// setReply adds the received bytes to the buffer.
+func setReply(rd *types.GXByteBuffer, reply any) error {
+	data, ok := reply.([]byte)
+	if !ok || data == nil {
+		return errors.New("no data received from the device")
+	}
+	return rd.Set(data)
+}
+
+// sleep waits the given time or until the context is cancelled.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// frameError tells which frame was in flight when the context was cancelled.
+func frameError(data []byte, err error) error {
+	return fmt.Errorf("frame %s: %w", types.ToHex(data, true), err)
+}
+
+// backoff returns the delay before the retry. Delay starts from base milliseconds and grows exponentially
+// with the attempt until BackoffMax is reached and jitter is added to it.
+func (r *GXDLMSReader) backoff(base int, attempt int) time.Duration {
+	delay := float64(base)
+	mult := r.BackoffMultiplier
+	if mult < 1 {
+		mult = 1
+	}
+	for i := 1; i < attempt; i++ {
+		delay *= mult
+		if r.BackoffMax > 0 && delay >= float64(r.BackoffMax) {
+			break
+		}
+	}
+	if r.BackoffMax > 0 && delay > float64(r.BackoffMax) {
+		delay = float64(r.BackoffMax)
+	}
+	return time.Duration(delay)*time.Millisecond + r.jitter()
+}
+
+// jitter returns a random delay between zero and Jitter milliseconds.
+// It's added to the retry delays so that collectors don't retry at the same time.
+func (r *GXDLMSReader) jitter() time.Duration {
+	if r.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.IntN(r.Jitter+1)) * time.Millisecond
+}
+
+// receiveCount returns the number of bytes that are waited in one receive.
+func (r *GXDLMSReader) receiveCount(rd *types.GXByteBuffer) int {
+	//UDP datagram is received as a whole. Frame size is not used because the
+	//whole WRAPPER PDU is in one datagram and more bytes would never arrive.
+	if r.isDatagram() {
+		return 1
+	}
+	//Replies are received in fixed size chunks that may contain several frames. GetData loop
+	//takes the frames from the chunk and waits for the rest if the chunk ends in the middle of the frame.
+	return max(r.client.GetFrameSize(rd), r.RxChunk)
+}
+
+// rxChunkPoll is the time that the chunk is waited before the received bytes are taken.
+const rxChunkPoll = 50 * time.Millisecond
+
+// receive receives the next bytes of the reply. With RxChunk the chunk is waited, but the reply
+// that is shorter than the chunk is taken when the chunk doesn't fill up.
+func (r *GXDLMSReader) receive(p *gxcommon.ReceiveParameters, rd *types.GXByteBuffer) (bool, error) {
+	if r.RxChunk <= 0 || r.isDatagram() {
+		return r.media.Receive(p)
+	}
+	count, waitTime := p.Count, p.WaitTime
+	defer func() {
+		p.Count, p.WaitTime = count, waitTime
+	}()
+	deadline := time.Now().Add(time.Duration(waitTime) * time.Millisecond)
+	for {
+		p.Count = count
+		p.WaitTime = int(min(rxChunkPoll, max(time.Until(deadline), time.Millisecond)).Milliseconds())
+		if ok, err := r.media.Receive(p); ok || err != nil {
+			return ok, err
+		}
+		//Rest of the frame is enough when the meter doesn't send more.
+		p.Count = max(r.client.GetFrameSize(rd), 1)
+		p.WaitTime = 0
+		if ok, err := r.media.Receive(p); ok || err != nil {
+			return ok, err
+		}
+		if !time.Now().Before(deadline) {
+			return false, nil
+		}
+	}
+}
+
+// frameReady returns true if the buffer contains a complete frame that GetData hasn't handled yet.
+func (r *GXDLMSReader) frameReady(rd *types.GXByteBuffer) bool {
+	return rd.Available() != 0 && r.client.GetFrameSize(rd) <= 0
+}
+
+// isDatagram returns true if the media is UDP.
+func (r *GXDLMSReader) isDatagram() bool {
+	m, ok := r.media.(*gxnet.GXNet)
+	return ok && m.Protocol == gxnet.NetworkTypeUDP
+}
+
 // ReadDataBlocks sends one or more data blocks to meter.
 func (r *GXDLMSReader) ReadDataBlocks(blocks [][]byte, reply *dlms.GXReplyData) (bool, error) {
+	return r.ReadDataBlocksContext(r.context(), blocks, reply)
+}
+
+// ReadDataBlocksContext sends one or more data blocks to meter until the context is cancelled.
+func (r *GXDLMSReader) ReadDataBlocksContext(ctx context.Context, blocks [][]byte, reply *dlms.GXReplyData) (bool, error) {
 	if blocks == nil {
 		return true, nil
 	}
 	for _, b := range blocks {
 		reply.Clear()
-		if err := r.ReadDataBlock(b, reply); err != nil {
+		if err := r.ReadDataBlockContext(ctx, b, reply); err != nil {
 			return false, err
 		}
 	}
@@ -747,7 +1814,12 @@ func (r *GXDLMSReader) ReadDataBlocks(blocks [][]byte, reply *dlms.GXReplyData)
 
 // ReadDataBlock sends one block and receives all follow-up blocks.
 func (r *GXDLMSReader) ReadDataBlock(data []byte, reply *dlms.GXReplyData) error {
-	if err := r.ReadDLMSPacket(data, reply); err != nil {
+	return r.ReadDataBlockContext(r.context(), data, reply)
+}
+
+// ReadDataBlockContext sends one block and receives all follow-up blocks until the context is cancelled.
+func (r *GXDLMSReader) ReadDataBlockContext(ctx context.Context, data []byte, reply *dlms.GXReplyData) error {
+	if err := r.ReadDLMSPacketContext(ctx, data, reply); err != nil {
 		return err
 	}
 	unlock := r.media.GetSynchronous()
@@ -764,7 +1836,7 @@ func (r *GXDLMSReader) ReadDataBlock(data []byte, reply *dlms.GXReplyData) error
 			}
 			data = next
 		}
-		if err := r.ReadDLMSPacket(data, reply); err != nil {
+		if err := r.ReadDLMSPacketContext(ctx, data, reply); err != nil {
 			return err
 		}
 	}
@@ -773,29 +1845,111 @@ func (r *GXDLMSReader) ReadDataBlock(data []byte, reply *dlms.GXReplyData) error
 
 // Read reads one COSEM attribute.
 func (r *GXDLMSReader) Read(obj objects.IGXDLMSBase, attributeIndex int) (any, error) {
+	return r.ReadContext(r.context(), obj, attributeIndex)
+}
+
+// ReadContext reads one COSEM attribute. Read is stopped when the context is cancelled.
+func (r *GXDLMSReader) ReadContext(ctx context.Context, obj objects.IGXDLMSBase, attributeIndex int) (value any, err error) {
 	if obj == nil {
-		return nil, errors.New("object is nil")
+		return nil, ErrObjectNil
 	}
 	if !r.client.CanRead(obj, attributeIndex) {
-		return nil, fmt.Errorf("cannot read %s index %d", obj.Base().String(), attributeIndex)
+		return nil, fmt.Errorf("%w %s index %d", ErrCannotRead, obj.Base().String(), attributeIndex)
+	}
+	if err := r.checkConformance(enums.ConformanceGet, enums.ConformanceRead); err != nil {
+		return nil, err
+	}
+	if r.Stats != nil {
+		start := time.Now()
+		defer func() {
+			r.Stats.addRead(obj.Base().LogicalName(), attributeIndex, time.Since(start), err)
+		}()
 	}
 	frames, err := r.client.Read(obj, attributeIndex)
 	if err != nil {
 		return nil, err
 	}
+	if obj.Base().ObjectType() == enums.ObjectTypeProfileGeneric && attributeIndex == 2 {
+		defer r.useProfileWaitTime()()
+	}
 	reply := dlms.NewGXReplyData()
-	if _, err = r.ReadDataBlocks(frames, reply); err != nil {
+	if _, err = r.ReadDataBlocksContext(ctx, frames, reply); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("read %s:%d: %w", obj.Base().LogicalName(), attributeIndex, err)
+		}
 		return nil, err
 	}
 	dt, err := obj.GetDataType(attributeIndex)
 	if err == nil && dt == enums.DataTypeNone {
 		obj.Base().SetDataType(attributeIndex, reply.DataType)
 	}
-	return r.client.UpdateValue(obj, attributeIndex, reply.Value, nil)
+	value, err = r.client.UpdateValue(obj, attributeIndex, reply.Value, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.publish(obj, attributeIndex, value)
+	return value, nil
+}
+
+// checkConformance returns an error if the meter didn't negotiate the service.
+// ln is used with LN referencing and sn with SN referencing.
+func (r *GXDLMSReader) checkConformance(ln, sn enums.Conformance) error {
+	negotiated := r.client.NegotiatedConformance()
+	//Conformance is unknown if the association is pre-established.
+	if negotiated == enums.ConformanceNone {
+		return nil
+	}
+	c := ln
+	if !r.client.UseLogicalNameReferencing() {
+		c = sn
+	}
+	if negotiated&c == 0 {
+		return &GXConformanceError{Conformance: c}
+	}
+	return nil
+}
+
+// checkSelectiveAccess returns an error if the meter didn't negotiate read with selective access.
+func (r *GXDLMSReader) checkSelectiveAccess() error {
+	if err := r.checkConformance(enums.ConformanceGet, enums.ConformanceRead); err != nil {
+		return err
+	}
+	return r.checkConformance(enums.ConformanceSelectiveAccess, enums.ConformanceParameterizedAccess)
+}
+
+// ReadWithSelector reads the attribute with selective access. Parameters are the access parameters
+// encoded as DLMS data, e.g. structure of the range or entry descriptor.
+func (r *GXDLMSReader) ReadWithSelector(obj objects.IGXDLMSBase, attributeIndex int, selector byte, parameters []byte) (any, error) {
+	if obj == nil {
+		return nil, ErrObjectNil
+	}
+	if !r.client.CanRead(obj, attributeIndex) {
+		return nil, fmt.Errorf("%w %s index %d", ErrCannotRead, obj.Base().String(), attributeIndex)
+	}
+	if err := r.checkSelectiveAccess(); err != nil {
+		return nil, err
+	}
+	frames, err := r.client.ReadWithSelector(obj, attributeIndex, selector, parameters)
+	if err != nil {
+		return nil, err
+	}
+	reply := dlms.NewGXReplyData()
+	if _, err = r.ReadDataBlocks(frames, reply); err != nil {
+		return nil, err
+	}
+	value, err := r.client.UpdateValue(obj, attributeIndex, reply.Value, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.publish(obj, attributeIndex, value)
+	return value, nil
 }
 
 // ReadList reads multiple attributes in one request sequence.
 func (r *GXDLMSReader) ReadList(list []types.GXKeyValuePair[objects.IGXDLMSBase, int]) error {
+	if err := r.checkConformance(enums.ConformanceMultipleReferences, enums.ConformanceMultipleReferences); err != nil {
+		return err
+	}
 	frames, err := r.client.ReadList(list)
 	if err != nil {
 		return err
@@ -816,49 +1970,185 @@ func (r *GXDLMSReader) ReadList(list []types.GXKeyValuePair[objects.IGXDLMSBase,
 	if len(values) != len(list) {
 		return fmt.Errorf("invalid reply count: got %d, expected %d", len(values), len(list))
 	}
-	return r.client.UpdateValues(list, values)
+	if err = r.client.UpdateValues(list, values); err != nil {
+		return err
+	}
+	for pos, it := range list {
+		r.publish(it.Key, it.Value, values[pos])
+	}
+	return nil
 }
 
 // Write writes one attribute value to the meter.
 func (r *GXDLMSReader) Write(obj objects.IGXDLMSBase, attributeIndex int) error {
 	if obj == nil {
-		return errors.New("object is nil")
+		return ErrObjectNil
 	}
 	if !r.client.CanWrite(obj, attributeIndex) {
-		return fmt.Errorf("cannot write %s index %d", obj.Base().String(), attributeIndex)
+		return fmt.Errorf("%w %s index %d", ErrCannotWrite, obj.Base().String(), attributeIndex)
 	}
 	frames, err := r.client.Write(obj, attributeIndex)
 	if err != nil {
 		return err
 	}
+	if r.client.ServiceClass() == enums.ServiceClassUnConfirmed {
+		return r.sendUnconfirmed(frames)
+	}
 	reply := dlms.NewGXReplyData()
 	_, err = r.ReadDataBlocks(frames, reply)
 	return err
 }
 
+// WriteList writes multiple attributes in one request sequence. Attributes are written one by one
+// if set with list is not negotiated. Returned value tells if the list was used.
+func (r *GXDLMSReader) WriteList(list []types.GXKeyValuePair[objects.IGXDLMSBase, int]) (bool, error) {
+	for _, it := range list {
+		if !r.client.CanWrite(it.Key, it.Value) {
+			return false, fmt.Errorf("%w %s index %d", ErrCannotWrite, it.Key.Base().String(), it.Value)
+		}
+	}
+	if r.client.NegotiatedConformance()&enums.ConformanceMultipleReferences == 0 {
+		for _, it := range list {
+			if err := r.Write(it.Key, it.Value); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+	frames, err := r.client.WriteList(list)
+	if err != nil {
+		return true, err
+	}
+	if r.client.ServiceClass() == enums.ServiceClassUnConfirmed {
+		return true, r.sendUnconfirmed(frames)
+	}
+	reply := dlms.NewGXReplyData()
+	_, err = r.ReadDataBlocks(frames, reply)
+	return true, err
+}
+
+// SendRaw sends the frame as it is and returns the received reply without parsing it.
+// DLMS state of the client is not updated, so the frame breaks the HDLC sequence numbers
+// and the invocation counter of the active association.
+func (r *GXDLMSReader) SendRaw(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("packet is empty")
+	}
+	if r.client.ConnectionState() != enums.ConnectionStateNone {
+		r.logger.Warn("raw frame is sent in the active association and the association state is not updated")
+	}
+	if !r.media.IsOpen() {
+		if err := openMedia(r.media); err != nil {
+			return nil, err
+		}
+	}
+	eop := any(byte(0x7E))
+	if r.client.InterfaceType() != enums.InterfaceTypeHDLC &&
+		r.client.InterfaceType() != enums.InterfaceTypeHdlcWithModeE {
+		eop = nil
+	}
+	unlock := r.media.GetSynchronous()
+	defer unlock()
+	p := gxcommon.NewReceiveParameters[[]byte]()
+	p.EOP = eop
+	p.AllData = true
+	p.WaitTime = r.WaitTime
+	for attempt := 0; attempt < max(r.RetryCount, 1); attempt++ {
+		rd := types.NewGXByteBuffer()
+		p.Reply = nil
+		p.Count = r.receiveCount(rd)
+		r.logger.Debug("frame", "direction", "TX", "bytes", len(data), "data", types.ToHex(data, true), "raw", true)
+		r.recordFrame(true, data)
+		r.writeHexDump(true, data)
+		if err := r.media.Send(data, ""); err != nil {
+			return nil, err
+		}
+		succeeded, err := r.receiveRaw(p, rd)
+		if err != nil {
+			return nil, err
+		}
+		if succeeded {
+			reply := rd.Array()
+			r.logger.Debug("frame", "direction", "RX", "bytes", len(reply), "data", types.ToHex(reply, true), "raw", true)
+			r.recordFrame(false, reply)
+			r.writeHexDump(false, reply)
+			return reply, nil
+		}
+	}
+	return nil, ErrReceiveTimeout
+}
+
+// receiveRaw receives one frame to rd. Frame is not parsed, so the frame size is used
+// to wait the rest of the frame in the same way as in readDLMSPacket.
+func (r *GXDLMSReader) receiveRaw(p *gxcommon.ReceiveParameters, rd *types.GXByteBuffer) (bool, error) {
+	for {
+		succeeded, err := r.media.Receive(p)
+		if err != nil || !succeeded {
+			return false, err
+		}
+		if err = setReply(rd, p.Reply); err != nil {
+			return false, err
+		}
+		//HDLC frame ends to EOP and UDP datagram is received as a whole.
+		if p.EOP != nil || r.isDatagram() || r.client.GetFrameSize(rd) <= 0 {
+			return true, nil
+		}
+		p.Count = r.receiveCount(rd)
+	}
+}
+
+// sendUnconfirmed sends the frames without waiting for the reply.
+// The meter doesn't reply to unconfirmed (broadcast) requests.
+func (r *GXDLMSReader) sendUnconfirmed(frames [][]byte) error {
+	for _, data := range frames {
+		r.logger.Debug("frame", "direction", "TX", "bytes", len(data), "data", types.ToHex(data, true), "unconfirmed", true)
+		r.recordFrame(true, data)
+		r.writeHexDump(true, data)
+		if err := r.media.Send(data, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Method invokes one COSEM method.
 func (r *GXDLMSReader) Method(obj objects.IGXDLMSBase, methodIndex int, value any) error {
+	_, err := r.MethodValue(obj, methodIndex, value)
+	return err
+}
+
+// MethodValue invokes one COSEM method and returns the value that the meter replies.
+func (r *GXDLMSReader) MethodValue(obj objects.IGXDLMSBase, methodIndex int, value any) (any, error) {
 	if obj == nil {
-		return errors.New("object is nil")
+		return nil, ErrObjectNil
 	}
 	if !r.client.CanInvoke(obj, methodIndex) {
-		return fmt.Errorf("cannot invoke %s method %d", obj.Base().String(), methodIndex)
+		return nil, fmt.Errorf("%w %s method %d", ErrCannotInvoke, obj.Base().String(), methodIndex)
 	}
 	frames, err := r.client.Method(obj, methodIndex, value, enums.DataTypeNone)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if r.client.ServiceClass() == enums.ServiceClassUnConfirmed {
+		return nil, r.sendUnconfirmed(frames)
 	}
 	reply := dlms.NewGXReplyData()
-	_, err = r.ReadDataBlocks(frames, reply)
-	return err
+	if _, err = r.ReadDataBlocks(frames, reply); err != nil {
+		return nil, err
+	}
+	return reply.Value, nil
 }
 
 // ReadRowsByEntry reads profile generic rows by entry range.
 func (r *GXDLMSReader) ReadRowsByEntry(pg *objects.GXDLMSProfileGeneric, index, count uint32) ([][]any, error) {
+	if err := r.checkSelectiveAccess(); err != nil {
+		return nil, err
+	}
 	frames, err := r.client.ReadRowsByEntry(pg, index, count)
 	if err != nil {
 		return nil, err
 	}
+	defer r.useProfileWaitTime()()
 	reply := dlms.NewGXReplyData()
 	if _, err = r.ReadDataBlocks(frames, reply); err != nil {
 		return nil, err
@@ -868,17 +2158,33 @@ func (r *GXDLMSReader) ReadRowsByEntry(pg *objects.GXDLMSProfileGeneric, index,
 		return nil, err
 	}
 	rows, _ := value.([][]any)
-	return rows, nil
+	return r.orderRows(pg, rows), nil
+}
+
+// useProfileWaitTime changes WaitTime to ProfileWaitTime and returns a function that restores it.
+func (r *GXDLMSReader) useProfileWaitTime() func() {
+	if r.ProfileWaitTime == 0 {
+		return func() {}
+	}
+	old := r.WaitTime
+	r.WaitTime = r.ProfileWaitTime
+	return func() {
+		r.WaitTime = old
+	}
 }
 
 // ReadRowsByRange reads profile generic rows by time range.
 func (r *GXDLMSReader) ReadRowsByRange(pg *objects.GXDLMSProfileGeneric,
 	start types.GXDateTime,
 	end types.GXDateTime) ([][]any, error) {
+	if err := r.checkSelectiveAccess(); err != nil {
+		return nil, err
+	}
 	frames, err := r.client.ReadRowsByRange(pg, start, end)
 	if err != nil {
 		return nil, err
 	}
+	defer r.useProfileWaitTime()()
 	reply := dlms.NewGXReplyData()
 	if _, err = r.ReadDataBlocks(frames, reply); err != nil {
 		return nil, err
@@ -888,7 +2194,161 @@ func (r *GXDLMSReader) ReadRowsByRange(pg *objects.GXDLMSProfileGeneric,
 		return nil, err
 	}
 	rows, _ := value.([][]any)
-	return rows, nil
+	return r.orderRows(pg, rows), nil
+}
+
+// ReadRowsBySelectiveAccess reads profile generic rows where the value of the capture object is
+// between from and to. Rows are selected by the meter with the range descriptor where the capture
+// object is the restricting object. Numbers, strings, octet strings and date-times are supported.
+func (r *GXDLMSReader) ReadRowsBySelectiveAccess(pg *objects.GXDLMSProfileGeneric,
+	captureObj objects.IGXDLMSBase,
+	from any,
+	to any) ([][]any, error) {
+	if pg == nil || captureObj == nil {
+		return nil, ErrObjectNil
+	}
+	column := slices.IndexFunc(pg.CaptureObjects, func(it *types.GXKeyValuePair[objects.IGXDLMSBase, *objects.GXDLMSCaptureObject]) bool {
+		return it.Key == captureObj
+	})
+	if column == -1 {
+		return nil, fmt.Errorf("%s is not captured by %s", captureObj.Base().LogicalName(), pg.Base().LogicalName())
+	}
+	parameters, err := r.rangeDescriptor(captureObj, pg.CaptureObjects[column].Value, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer r.useProfileWaitTime()()
+	value, err := r.ReadWithSelector(pg, 2, 1, parameters)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := value.([][]any)
+	return r.orderRows(pg, rows), nil
+}
+
+// rangeDescriptor encodes the range descriptor of the selective access. All columns are selected.
+func (r *GXDLMSReader) rangeDescriptor(target objects.IGXDLMSBase, co *objects.GXDLMSCaptureObject, from any, to any) ([]byte, error) {
+	ln := types.HexToBytes(lnToHex(target.Base().LogicalName()))
+	buff := []byte{
+		//Range descriptor and the restricting object.
+		byte(enums.DataTypeStructure), 4,
+		byte(enums.DataTypeStructure), 4,
+		byte(enums.DataTypeUint16), 0, 0,
+		byte(enums.DataTypeOctetString), byte(len(ln)),
+	}
+	binary.BigEndian.PutUint16(buff[5:], uint16(target.Base().ObjectType()))
+	buff = append(buff, ln...)
+	buff = append(buff, byte(enums.DataTypeInt8), byte(co.AttributeIndex), byte(enums.DataTypeUint16))
+	buff = binary.BigEndian.AppendUint16(buff, uint16(co.DataIndex))
+	for _, it := range []any{from, to} {
+		v, err := r.encodeRangeValue(it)
+		if err != nil {
+			return nil, err
+		}
+		buff = append(buff, v...)
+	}
+	//Empty array selects all columns.
+	return append(buff, byte(enums.DataTypeArray), 0), nil
+}
+
+// encodeRangeValue encodes the from or to value of the range descriptor.
+func (r *GXDLMSReader) encodeRangeValue(value any) ([]byte, error) {
+	var buff []byte
+	switch v := value.(type) {
+	case types.GXDateTime:
+		buff = append([]byte{byte(enums.DataTypeOctetString), 12}, r.encodeDateTime(v.Value)...)
+	case *types.GXDateTime:
+		buff = append([]byte{byte(enums.DataTypeOctetString), 12}, r.encodeDateTime(v.Value)...)
+	case time.Time:
+		buff = append([]byte{byte(enums.DataTypeOctetString), 12}, r.encodeDateTime(v)...)
+	case int8:
+		buff = []byte{byte(enums.DataTypeInt8), byte(v)}
+	case uint8:
+		buff = []byte{byte(enums.DataTypeUint8), v}
+	case int16:
+		buff = binary.BigEndian.AppendUint16([]byte{byte(enums.DataTypeInt16)}, uint16(v))
+	case uint16:
+		buff = binary.BigEndian.AppendUint16([]byte{byte(enums.DataTypeUint16)}, v)
+	case int32:
+		buff = binary.BigEndian.AppendUint32([]byte{byte(enums.DataTypeInt32)}, uint32(v))
+	case uint32:
+		buff = binary.BigEndian.AppendUint32([]byte{byte(enums.DataTypeUint32)}, v)
+	case int:
+		buff = binary.BigEndian.AppendUint64([]byte{byte(enums.DataTypeInt64)}, uint64(v))
+	case int64:
+		buff = binary.BigEndian.AppendUint64([]byte{byte(enums.DataTypeInt64)}, uint64(v))
+	case uint64:
+		buff = binary.BigEndian.AppendUint64([]byte{byte(enums.DataTypeUint64)}, v)
+	case float32:
+		buff = binary.BigEndian.AppendUint32([]byte{byte(enums.DataTypeFloat32)}, math.Float32bits(v))
+	case float64:
+		buff = binary.BigEndian.AppendUint64([]byte{byte(enums.DataTypeFloat64)}, math.Float64bits(v))
+	case string:
+		if len(v) > 127 {
+			return nil, fmt.Errorf("range value %q is too long", v)
+		}
+		buff = append([]byte{byte(enums.DataTypeString), byte(len(v))}, v...)
+	case []byte:
+		if len(v) > 127 {
+			return nil, fmt.Errorf("range value %s is too long", types.ToHex(v, true))
+		}
+		buff = append([]byte{byte(enums.DataTypeOctetString), byte(len(v))}, v...)
+	default:
+		return nil, fmt.Errorf("range value %v of type %T is not supported", value, value)
+	}
+	return buff, nil
+}
+
+// IsConnected checks that the media is open and the association is still alive.
+// The clock is read if it's available in the association view.
+func (r *GXDLMSReader) IsConnected() bool {
+	if r.media == nil || !r.media.IsOpen() || r.client.ConnectionState() == enums.ConnectionStateNone {
+		return false
+	}
+	clocks := r.client.Objects().GetObjects(enums.ObjectTypeClock)
+	if len(clocks) == 0 {
+		return true
+	}
+	_, err := r.Read(clocks[0], 2)
+	return err == nil
+}
+
+// KeepAlive reads the clock so the meter or the gateway doesn't close the idle connection.
+func (r *GXDLMSReader) KeepAlive() error {
+	var clock objects.IGXDLMSBase
+	if clocks := r.client.Objects().GetObjects(enums.ObjectTypeClock); len(clocks) != 0 {
+		clock = clocks[0]
+	} else {
+		c, err := objects.NewGXDLMSClock("0.0.1.0.0.255", 0)
+		if err != nil {
+			return err
+		}
+		clock = c
+	}
+	_, err := r.Read(clock, 2)
+	return err
+}
+
+// WaitWithKeepAlive waits until the deadline and sends keep-alive read after each interval.
+// Error is returned if the keep-alive read fails so the caller can reconnect.
+func (r *GXDLMSReader) WaitWithKeepAlive(deadline time.Time, interval time.Duration) error {
+	for {
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil
+		}
+		if wait <= interval {
+			return sleep(r.context(), wait)
+		}
+		if err := sleep(r.context(), interval); err != nil {
+			return err
+		}
+		if err := r.KeepAlive(); err != nil {
+			r.logger.Warn("keep-alive failed", "error", err)
+			return err
+		}
+		r.logger.Debug("keep-alive")
+	}
 }
 
 // Release sends release request if the connection type needs it.
@@ -916,7 +2376,7 @@ func (r *GXDLMSReader) Disconnect() error {
 	}
 	err := r.Release()
 	if err != nil {
-		r.writeTrace(fmt.Sprintf("Release failed: %v\n", err))
+		r.logger.Debug("release failed", "error", err)
 		// Ignore release failures for meters that do not support release.
 	}
 	frame, err := r.client.DisconnectRequest()
@@ -930,33 +2390,25 @@ func (r *GXDLMSReader) Disconnect() error {
 	return r.ReadDLMSPacket(frame, reply)
 }
 
+// disconnectTimeout is the maximum time that disconnecting may take on shutdown.
+const disconnectTimeout = 5 * time.Second
+
 // Close closes connection and media.
 func (r *GXDLMSReader) Close() error {
 	if r.media == nil {
 		return nil
 	}
+	if ctx := r.context(); ctx.Err() != nil {
+		//Reads are cancelled on shutdown, but the association is still released
+		//so the meter is not left with a hanging association.
+		var cancel context.CancelFunc
+		r.Context, cancel = context.WithTimeout(context.WithoutCancel(ctx), disconnectTimeout)
+		defer cancel()
+	}
 	_ = r.Disconnect()
+	r.saveInvocationCounter()
 	err := r.media.Close()
 	r.media = nil
 	r.client = nil
 	return err
 }
-
-func (r *GXDLMSReader) writeTrace(line string) {
-	if r.trace > gxcommon.TraceLevelInfo {
-		fmt.Println(line)
-	}
-	if r.traceFile == "" {
-		return
-	}
-	f, err := os.OpenFile(r.traceFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
-		return
-	}
-	defer func() {
-		if closeErr := f.Close(); closeErr != nil {
-			fmt.Printf("failed to close trace file: %v\n", closeErr)
-		}
-	}()
-	_, _ = fmt.Fprintln(f, line)
-}
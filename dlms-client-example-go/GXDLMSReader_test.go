@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	dlms "github.com/Gurux/gxdlms-go"
+	"github.com/Gurux/gxdlms-go/enums"
+)
+
+// wrapperFrame returns the WRAPPER frame between the client address 16 and the server address 1.
+func wrapperFrame(apdu []byte, fromMeter bool) []byte {
+	source, target := uint16(16), uint16(1)
+	if fromMeter {
+		source, target = target, source
+	}
+	frame := binary.BigEndian.AppendUint16(nil, 1)
+	frame = binary.BigEndian.AppendUint16(frame, source)
+	frame = binary.BigEndian.AppendUint16(frame, target)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(len(apdu)))
+	return append(frame, apdu...)
+}
+
+func TestReadDLMSPacketTwoFramesInOneChunk(t *testing.T) {
+	request := wrapperFrame(testGetRequest, false)
+	//Data notification with octet string "ABC" is received before the get response.
+	notification := wrapperFrame([]byte{0x0F, 0x00, 0x00, 0x00, 0x01, 0x00, 0x09, 0x03, 0x41, 0x42, 0x43}, true)
+	response := wrapperFrame(testGetResponse, true)
+	media := NewGXMockMedia(GXMockExchange{TX: request, RX: append(notification, response...)})
+	r := newTestReader(t, media, enums.InterfaceTypeWRAPPER)
+	//Chunk is larger than both frames together.
+	r.RxChunk = 256
+	var notified []any
+	r.OnNotification = func(value any) {
+		notified = append(notified, value)
+	}
+	reply := dlms.NewGXReplyData()
+	if err := r.ReadDLMSPacket(request, reply); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(reply.Value); got != "1234" {
+		t.Errorf("received %s, expected 1234", got)
+	}
+	if len(notified) != 1 {
+		t.Errorf("received %d notifications, expected 1", len(notified))
+	}
+	if len(r.pending) != 0 {
+		t.Errorf("%d bytes were left to the next reply", len(r.pending))
+	}
+	if err := media.Done(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRetryBusyBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       enums.ErrorCode
+		retryCount int
+		//Expected delays between the retries.
+		delays []time.Duration
+	}{
+		{"rejected is retried once by default", enums.ErrorCodeRejected, 0, []time.Duration{time.Second}},
+		{"temporary failure is not retried by default", enums.ErrorCodeTemporaryFailure, 0, nil},
+		{"delay grows until maximum", enums.ErrorCodeTemporaryFailure, 4,
+			[]time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}},
+		{"rejected uses retry count", enums.ErrorCodeRejected, 3,
+			[]time.Duration{time.Second, 2 * time.Second, 4 * time.Second}},
+	}
+	defer func(old func(context.Context, time.Duration) error) {
+		sleep = old
+	}(sleep)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delays []time.Duration
+			sleep = func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+			r := NewGXDLMSReader(nil, nil, gxcommon.TraceLevelOff, "", 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			r.TempRetryCount = tt.retryCount
+			r.BackoffMax = 5000
+			sent := 0
+			err := r.retryBusy(context.Background(), nil, func() error {
+				sent++
+				return &GXDLMSError{Code: tt.code}
+			})
+			if !errors.Is(err, tt.code) {
+				t.Errorf("returned %v, expected %v", err, tt.code)
+			}
+			if sent != len(tt.delays)+1 {
+				t.Errorf("sent %d times, expected %d", sent, len(tt.delays)+1)
+			}
+			if !slices.Equal(delays, tt.delays) {
+				t.Errorf("delays %v, expected %v", delays, tt.delays)
+			}
+		})
+	}
+}
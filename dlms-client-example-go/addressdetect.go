@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+
+	dlms "github.com/Gurux/gxdlms-go"
+	"github.com/Gurux/gxdlms-go/enums"
+)
+
+// gxAddressCandidate is HDLC server address that is tried when the address is detected.
+type gxAddressCandidate struct {
+	// Scheme is the size of the server address.
+	Scheme string
+	// Logical is the logical device address.
+	Logical int
+	// Physical is the physical device address. One byte address doesn't have it.
+	Physical int
+}
+
+// addressCandidates are the common one-byte, two-byte and four-byte server addresses.
+// 0x3FFF is the all-station address of the four-byte addressing.
+var addressCandidates = []gxAddressCandidate{
+	{Scheme: "one-byte", Logical: 1},
+	{Scheme: "two-byte", Logical: 1, Physical: 1},
+	{Scheme: "two-byte", Logical: 1, Physical: 16},
+	{Scheme: "two-byte", Logical: 1, Physical: 17},
+	{Scheme: "four-byte", Logical: 1, Physical: 0x3FFF},
+}
+
+// serverAddress returns the HDLC server address of the candidate.
+func (c gxAddressCandidate) serverAddress() (int, error) {
+	if c.Scheme == "one-byte" {
+		return c.Logical, nil
+	}
+	return dlms.GetServerAddress(c.Logical, c.Physical)
+}
+
+// DetectAddress sends SNRM with the common client and server addresses and returns the first
+// client address and server address that the meter accepts with UA.
+// Connection is closed after the address is found.
+func (r *GXDLMSReader) DetectAddress() (int, gxAddressCandidate, error) {
+	if r.client.InterfaceType() != enums.InterfaceTypeHDLC &&
+		r.client.InterfaceType() != enums.InterfaceTypeHdlcWithModeE {
+		return 0, gxAddressCandidate{}, errors.New("address detection needs HDLC interface")
+	}
+	if !r.media.IsOpen() {
+		if err := openMedia(r.media); err != nil {
+			return 0, gxAddressCandidate{}, err
+		}
+	}
+	if err := r.initializeOpticalHead(); err != nil {
+		return 0, gxAddressCandidate{}, err
+	}
+	//Each address is tried only once so detection doesn't take too long.
+	retryCount := r.RetryCount
+	r.RetryCount = 1
+	defer func() {
+		r.RetryCount = retryCount
+	}()
+	clients := []int{r.client.ClientAddress()}
+	if clients[0] != 16 {
+		//Public client.
+		clients = append(clients, 16)
+	}
+	for _, client := range clients {
+		for _, it := range addressCandidates {
+			server, err := it.serverAddress()
+			if err != nil {
+				return 0, gxAddressCandidate{}, err
+			}
+			if err = r.client.SetClientAddress(client); err != nil {
+				return 0, gxAddressCandidate{}, err
+			}
+			if err = r.client.SetServerAddress(server); err != nil {
+				return 0, gxAddressCandidate{}, err
+			}
+			if err = r.SNRMRequest(); err != nil {
+				r.logger.Debug("address not accepted", "client", client, "server", server, "scheme", it.Scheme, "error", err)
+				continue
+			}
+			if frame, err := r.client.DisconnectRequest(); err == nil && frame != nil {
+				_ = r.ReadDLMSPacket(frame, dlms.NewGXReplyData())
+			}
+			return client, it, nil
+		}
+	}
+	return 0, gxAddressCandidate{}, errors.New("meter didn't reply to any of the common HDLC addresses")
+}
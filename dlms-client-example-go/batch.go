@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gxBatchResult is the result of one meter in batch mode.
+type gxBatchResult struct {
+	Line int
+	Name string
+	Err  error
+	Took time.Duration
+}
+
+// readMeterFile reads the meter settings from the file. Each line has the flags of one meter.
+// Values with spaces are quoted like in the shell. Empty lines and lines starting with # are skipped.
+func readMeterFile(path string) ([]*gxSettings, []int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	var meters []*gxSettings
+	var lines []int
+	scanner := bufio.NewScanner(f)
+	for pos := 1; scanner.Scan(); pos++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		args, err := splitArgs(line)
+		var s *gxSettings
+		if err == nil {
+			s, err = getParameters(args)
+		}
+		if err == nil && (s == nil || s.media == nil) {
+			err = errors.New("connection is not given")
+		}
+		if err != nil {
+			closeMeters(meters)
+			return nil, nil, fmt.Errorf("%s:%d: %w", path, pos, err)
+		}
+		meters = append(meters, s)
+		lines = append(lines, pos)
+	}
+	if err = scanner.Err(); err != nil {
+		closeMeters(meters)
+		return nil, nil, err
+	}
+	return meters, lines, nil
+}
+
+// splitArgs splits the meter line to the arguments like the shell does. Single and double quotes
+// group the characters and backslash escapes a quote or backslash outside single quotes.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	//Quote character or zero if the argument is not quoted.
+	var quote rune
+	inArg, escaped := false, false
+	for _, c := range line {
+		switch {
+		case escaped:
+			//Backslash is kept if it doesn't escape a quote or backslash, e.g. in Windows paths.
+			if c != '"' && c != '\'' && c != '\\' {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote, inArg = c, true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	if escaped {
+		arg.WriteRune('\\')
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// closeMeters closes the value sinks and the signers that the meter lines have opened.
+func closeMeters(meters []*gxSettings) {
+	for _, it := range meters {
+		it.close()
+	}
+}
+
+// meterOutputFile returns the output file of the meter. It's made from the media name if -o
+// or --out-template is not given.
+func meterOutputFile(s *gxSettings) string {
+	if s.outputFile != "" || s.outTemplate != "" {
+		return s.outputFile
+	}
+	return safeFileName(s.media.GetName()) + ".xml"
+}
+
+// safeFileName replaces the characters that can't be used in the file name.
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:/\*?"<>| `, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// runBatch reads all meters in the file. Parallel meters are read at the same time.
+// Output template is used for the meters that don't have their own -o or --out-template.
+// Console output of each meter is collected and written at once when the meter is read,
+// so the output of the parallel meters is not mixed.
+func runBatch(ctx context.Context, path string, parallel int, outTemplate string) error {
+	meters, lines, err := readMeterFile(path)
+	if err != nil {
+		return err
+	}
+	defer closeMeters(meters)
+	results := make([]gxBatchResult, len(meters))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	var outputMu sync.Mutex
+	for pos, s := range meters {
+		if s.outputFile == "" && s.outTemplate == "" {
+			s.outTemplate = outTemplate
+		}
+		s.outputFile = meterOutputFile(s)
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var output bytes.Buffer
+			s.console = &output
+			start := time.Now()
+			err := readMeter(ctx, s)
+			results[pos] = gxBatchResult{Line: lines[pos], Name: s.media.GetName(), Err: err, Took: time.Since(start)}
+			outputMu.Lock()
+			defer outputMu.Unlock()
+			fmt.Fprintf(os.Stderr, "line %d %s:\n", lines[pos], s.media.GetName())
+			_, _ = output.WriteTo(os.Stderr)
+		}()
+	}
+	wg.Wait()
+	failed := 0
+	fmt.Fprintln(os.Stderr, "Summary:")
+	for _, it := range results {
+		if it.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "  line %d %s failed in %s: %v\n", it.Line, it.Name, it.Took.Round(time.Millisecond), it.Err)
+		} else {
+			fmt.Fprintf(os.Stderr, "  line %d %s succeeded in %s.\n", it.Line, it.Name, it.Took.Round(time.Millisecond))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d meters read. %d succeeded, %d failed.\n", len(results), len(results)-failed, failed)
+	if failed != 0 {
+		return fmt.Errorf("%d meters failed", failed)
+	}
+	return nil
+}
+
+// readMeter connects to one meter and reads all objects.
+func readMeter(ctx context.Context, s *gxSettings) error {
+	//Meters that are not started yet are not read after the application is stopped.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	reader := newReader(ctx, s)
+	defer reader.Close()
+	if err := openMedia(s.media); err != nil {
+		return err
+	}
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	return readAll(reader, s)
+}
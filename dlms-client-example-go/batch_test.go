@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{"plain flags", "-h localhost  -p 4059", []string{"-h", "localhost", "-p", "4059"}},
+		{"quoted value", `-h localhost -P "pass word" -o 'meter 1.xml'`,
+			[]string{"-h", "localhost", "-P", "pass word", "-o", "meter 1.xml"}},
+		{"quote inside value", `-P "a\"b" -P 'it"s'`, []string{"-P", `a"b`, "-P", `it"s`}},
+		{"empty quoted value", `-P ""`, []string{"-P", ""}},
+		{"windows path", `-o C:\meters\meter1.xml`, []string{"-o", `C:\meters\meter1.xml`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := splitArgs(tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(args, tt.expected) {
+				t.Errorf("returned %q, expected %q", args, tt.expected)
+			}
+		})
+	}
+	if _, err := splitArgs(`-P "pass word`); err == nil {
+		t.Error("unterminated quote was accepted")
+	}
+}
+
+func TestReadMeterFileQuotedValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meters.txt")
+	if err := os.WriteFile(path, []byte("# Meters\n-h localhost -p 4059 -o \"meter 1.xml\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	meters, lines, err := readMeterFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeMeters(meters)
+	if len(meters) != 1 || lines[0] != 2 {
+		t.Fatalf("read %d meters from lines %v, expected one meter from line 2", len(meters), lines)
+	}
+	if meters[0].outputFile != "meter 1.xml" {
+		t.Errorf("output file %q, expected %q", meters[0].outputFile, "meter 1.xml")
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	dlms "github.com/Gurux/gxdlms-go"
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// cacheFingerprintPrefix starts the XML comment that identifies the meter of the association view cache.
+const cacheFingerprintPrefix = "<!-- fingerprint "
+
+// cacheFingerprint returns the fingerprint of the connected meter. It contains the system title,
+// the logical device name and the number of the objects in the association view of the meter.
+// The object count changes when the object set of the meter changes, e.g. after the firmware update.
+func (r *GXDLMSReader) cacheFingerprint() string {
+	return fmt.Sprintf("systemTitle=%s ldn=%s objects=%d",
+		types.ToHex(r.client.Ciphering().RecipientSystemTitle(), false),
+		types.ToHex(r.logicalDeviceName(), false),
+		r.viewCount)
+}
+
+// meterObjectCount reads the first block of the association view and returns the number of
+// the objects that the meter tells. The rest of the blocks are not read.
+func (r *GXDLMSReader) meterObjectCount() (int, error) {
+	frames, err := r.client.GetObjectsRequest()
+	if err != nil || len(frames) == 0 {
+		return 0, err
+	}
+	reply := dlms.NewGXReplyData()
+	data := frames[0]
+	for {
+		if err = r.ReadDLMSPacket(data, reply); err != nil {
+			return 0, err
+		}
+		//Array header is available when the first block is received.
+		if count := associationViewCount(reply.Data.Array()); count != 0 || !reply.IsMoreData() {
+			return count, nil
+		}
+		if data, err = r.client.ReceiverReady(reply); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// logicalDeviceName returns the logical device name of the connected meter. It's read only once.
+// Empty name is returned if the meter doesn't have the logical device name object.
+func (r *GXDLMSReader) logicalDeviceName() []byte {
+	if r.deviceName != nil {
+		return r.deviceName
+	}
+	r.deviceName = []byte{}
+	var obj objects.IGXDLMSBase
+	if len(*r.client.Objects()) != 0 {
+		obj = r.client.Objects().FindByLN(enums.ObjectTypeData, "0.0.42.0.0.255")
+	} else if d, err := objects.NewGXDLMSData("0.0.42.0.0.255", 0); err == nil {
+		//Association view is not read yet.
+		obj = d
+	}
+	if obj != nil && r.client.CanRead(obj, 2) {
+		switch v, _ := r.Read(obj, 2); v := v.(type) {
+		case []byte:
+			r.deviceName = v
+		case string:
+			r.deviceName = []byte(v)
+		}
+	}
+	return r.deviceName
+}
+
+// associationViewCount returns the number of the objects that the association view array tells.
+// Zero is returned if the data is not an array.
+func associationViewCount(data []byte) int {
+	if len(data) < 2 || data[0] != byte(enums.DataTypeArray) {
+		return 0
+	}
+	//Length is one byte if it's less than 0x80. Otherwise the low bits tell the size of the length.
+	if data[1] < 0x80 {
+		return int(data[1])
+	}
+	size := int(data[1] & 0x7F)
+	if size > 4 || len(data) < 2+size {
+		return 0
+	}
+	count := 0
+	for _, it := range data[2 : 2+size] {
+		count = count<<8 | int(it)
+	}
+	return count
+}
+
+// logMissingObjects logs the objects of the partially read association view. Objects of the previous
+// cache that were not received are logged one by one.
+func (r *GXDLMSReader) logMissingObjects(expected int, cached []objects.IGXDLMSBase) {
+	r.logger.Warn("association view is incomplete", "objects", len(*r.client.Objects()), "expected", expected)
+	for _, it := range cached {
+		if r.client.Objects().FindByLN(it.Base().ObjectType(), it.Base().LogicalName()) == nil {
+			r.logger.Warn("object is missing", "ln", it.Base().LogicalName(), "type", it.Base().ObjectType().String())
+		}
+	}
+}
+
+// readCacheFingerprint returns the fingerprint that is saved to the cache file.
+func readCacheFingerprint(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	pos := bytes.LastIndex(data, []byte(cacheFingerprintPrefix))
+	if pos == -1 {
+		return "", false
+	}
+	value, _, ok := strings.Cut(string(data[pos+len(cacheFingerprintPrefix):]), " -->")
+	return value, ok
+}
+
+// cacheMatches returns true if the loaded cache was saved from the connected meter and
+// the meter has the same number of the objects.
+func (r *GXDLMSReader) cacheMatches(path string) bool {
+	saved, ok := readCacheFingerprint(path)
+	if !ok {
+		return false
+	}
+	count, err := r.meterObjectCount()
+	if err != nil {
+		r.logger.Warn("reading the number of the objects failed", "error", err)
+		return false
+	}
+	r.viewCount = count
+	return saved == r.cacheFingerprint()
+}
+
+// saveCache saves the association view and appends the fingerprint of the meter to the cache file.
+func (r *GXDLMSReader) saveCache(path string, settings *objects.GXXmlWriterSettings) error {
+	if err := r.client.Objects().SaveToFile(path, settings); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(f, "\n%s%s -->\n", cacheFingerprintPrefix, r.cacheFingerprint()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gurux/gxdlms-go/enums"
+)
+
+func TestCacheMatchesObjectCount(t *testing.T) {
+	//Get request of the association view 0.0.40.0.0.255.
+	request := []byte{0xC0, 0x01, 0xC1, 0x00, 0x0F, 0x00, 0x00, 0x28, 0x00, 0x00, 0xFF, 0x02, 0x00}
+	//First block of the association view. The array has three objects.
+	firstBlock := []byte{0xC4, 0x02, 0xC1, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x07,
+		0x01, 0x03, 0x02, 0x04, 0x12, 0x00, 0x08}
+	tests := []struct {
+		name     string
+		saved    string
+		expected bool
+	}{
+		{"same object count", "systemTitle= ldn=414243 objects=3", true},
+		{"object count has changed", "systemTitle= ldn=414243 objects=2", false},
+		{"object count is not saved", "systemTitle= ldn=414243", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cache.xml")
+			if err := os.WriteFile(path, []byte("<Objects>\n</Objects>\n"+cacheFingerprintPrefix+tt.saved+" -->\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			media := NewGXMockMedia(
+				GXMockExchange{TX: testSNRM, RX: hdlcFrame(0x73, testUA, false)},
+				GXMockExchange{TX: hdlcRequest(0x10, testAARQ), RX: hdlcFrame(0x30, testAAREAccepted, true)},
+				GXMockExchange{TX: hdlcRequest(0x32, request), RX: hdlcFrame(0x52, firstBlock, true)})
+			r := newTestReader(t, media, enums.InterfaceTypeHDLC)
+			if err := r.SNRMRequest(); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.AarqRequest(); err != nil {
+				t.Fatalf("association failed: %v", err)
+			}
+			//Logical device name is known so it's not read.
+			r.deviceName = []byte("ABC")
+			if got := r.cacheMatches(path); got != tt.expected {
+				t.Errorf("returned %v, expected %v", got, tt.expected)
+			}
+			if err := media.Done(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
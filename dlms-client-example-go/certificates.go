@@ -0,0 +1,211 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// ExportCertificates exports client and server certificates from the security setup and saves them
+// as .cer (DER) and .pem files. Files are named after the meter system title.
+func (r *GXDLMSReader) ExportCertificates(ss *objects.GXDLMSSecuritySetup) ([]string, error) {
+	if ss == nil {
+		return nil, fmt.Errorf("security setup is nil")
+	}
+	//Read system titles.
+	for _, idx := range []int{4, 5} {
+		if r.client.CanRead(ss, idx) {
+			if _, err := r.Read(ss, idx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	name := types.ToHex(ss.ServerSystemTitle, false)
+	if name == "" {
+		name = types.ToHex(r.client.Ciphering().RecipientSystemTitle(), false)
+	}
+	if name == "" {
+		name = "meter"
+	}
+	var files []string
+	for _, entity := range []enums.CertificateEntity{enums.CertificateEntityClient, enums.CertificateEntityServer} {
+		systemTitle := ss.ServerSystemTitle
+		if entity == enums.CertificateEntityClient {
+			systemTitle = ss.ClientSystemTitle
+		}
+		for _, ct := range []enums.CertificateType{enums.CertificateTypeDigitalSignature, enums.CertificateTypeKeyAgreement} {
+			der, err := r.exportCertificate(ss, entity, ct, systemTitle)
+			if err != nil {
+				r.logger.Debug("export certificate failed", "entity", entity.String(), "type", ct.String(), "error", err)
+				continue
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return files, fmt.Errorf("invalid %s %s certificate: %w", entity.String(), ct.String(), err)
+			}
+			r.logger.Debug("certificate", "entity", entity.String(), "type", ct.String(),
+				"subject", cert.Subject.String(), "serial", cert.SerialNumber.String())
+			base := fmt.Sprintf("%s_%s_%s", name, entity.String(), ct.String())
+			if err = os.WriteFile(base+".cer", der, 0o644); err != nil {
+				return files, err
+			}
+			if err = os.WriteFile(base+".pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+				return files, err
+			}
+			files = append(files, base+".cer", base+".pem")
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no certificates exported from %s", ss.Base().LogicalName())
+	}
+	return files, nil
+}
+
+// exportCertificate invokes export certificate method by the certificate entity and type.
+func (r *GXDLMSReader) exportCertificate(ss *objects.GXDLMSSecuritySetup,
+	entity enums.CertificateEntity,
+	certificateType enums.CertificateType,
+	systemTitle []byte) ([]byte, error) {
+	//Certificate is identified by entity.
+	value := types.GXStructure{
+		types.GXEnum{Value: 0},
+		types.GXStructure{
+			types.GXEnum{Value: uint8(entity)},
+			types.GXEnum{Value: uint8(certificateType)},
+			systemTitle,
+		},
+	}
+	ret, err := r.MethodValue(ss, 7, value)
+	if err != nil {
+		return nil, err
+	}
+	der, ok := ret.([]byte)
+	if !ok || len(der) == 0 {
+		return nil, fmt.Errorf("certificate not returned")
+	}
+	return der, nil
+}
+
+// GenerateCertificates generates a new client key pair, asks the meter to generate its own key agreement
+// key pair and imports the certificates to the meter and to the client ciphering.
+func (r *GXDLMSReader) GenerateCertificates(ss *objects.GXDLMSSecuritySetup) error {
+	if ss == nil {
+		return fmt.Errorf("security setup is nil")
+	}
+	if !r.client.CanInvoke(ss, 4) || !r.client.CanInvoke(ss, 5) || !r.client.CanInvoke(ss, 6) {
+		return ErrCertificateGeneration
+	}
+	//Read security suite and system titles.
+	for _, idx := range []int{3, 4, 5} {
+		if r.client.CanRead(ss, idx) {
+			if _, err := r.Read(ss, idx); err != nil {
+				return err
+			}
+		}
+	}
+	curve := elliptic.P256()
+	if ss.SecuritySuite == enums.SecuritySuite2 {
+		curve = elliptic.P384()
+	}
+	r.logger.Debug("generating client key pair")
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return err
+	}
+	systemTitle := ss.ClientSystemTitle
+	if len(systemTitle) == 0 {
+		systemTitle = r.client.Ciphering().SystemTitle()
+	}
+	template, err := certificateTemplate(pkix.Name{CommonName: types.ToHex(systemTitle, false)})
+	if err != nil {
+		return err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement | x509.KeyUsageCertSign
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	clientCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+	r.logger.Debug("generating key agreement key pair in the meter")
+	if _, err = r.MethodValue(ss, 4, types.GXEnum{Value: uint8(enums.CertificateTypeKeyAgreement)}); err != nil {
+		return fmt.Errorf("%w: %v", ErrCertificateGeneration, err)
+	}
+	r.logger.Debug("requesting certificate signing request from the meter")
+	ret, err := r.MethodValue(ss, 5, types.GXEnum{Value: uint8(enums.CertificateTypeKeyAgreement)})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCertificateGeneration, err)
+	}
+	data, ok := ret.([]byte)
+	if !ok || len(data) == 0 {
+		return fmt.Errorf("%w: certificate signing request not returned", ErrCertificateGeneration)
+	}
+	csr, err := x509.ParseCertificateRequest(data)
+	if err != nil {
+		return fmt.Errorf("invalid certificate signing request: %w", err)
+	}
+	if err = csr.CheckSignature(); err != nil {
+		return fmt.Errorf("invalid certificate signing request: %w", err)
+	}
+	r.logger.Debug("signing meter certificate", "subject", csr.Subject.String())
+	template, err = certificateTemplate(csr.Subject)
+	if err != nil {
+		return err
+	}
+	template.KeyUsage = x509.KeyUsageKeyAgreement
+	der, err = x509.CreateCertificate(rand.Reader, template, clientCert, csr.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	serverCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return err
+	}
+	r.logger.Debug("importing client certificate to the meter")
+	if err = r.Method(ss, 6, clientCert.Raw); err != nil {
+		return err
+	}
+	r.logger.Debug("importing meter certificate to the meter")
+	if err = r.Method(ss, 6, serverCert.Raw); err != nil {
+		return err
+	}
+	r.logger.Debug("importing certificates to the client")
+	c := r.client.Ciphering()
+	if err = c.SetKeyAgreementKeyPair(key); err != nil {
+		return err
+	}
+	if err = c.AddCertificate(clientCert); err != nil {
+		return err
+	}
+	return c.AddCertificate(serverCert)
+}
+
+// certificateTemplate returns a certificate template with a random serial number.
+func certificateTemplate(subject pkix.Name) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    now,
+		NotAfter:     now.AddDate(10, 0, 0),
+	}, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// maxShiftTime is the largest drift that the shift_time method of the clock can correct.
+const maxShiftTime = 900 * time.Second
+
+// ClockDrift reads the time of the meter and returns how much it differs from the host time.
+// Positive drift means that the meter clock is ahead.
+func (r *GXDLMSReader) ClockDrift(clock *objects.GXDLMSClock) (time.Duration, error) {
+	start := time.Now()
+	if _, err := r.Read(clock, 2); err != nil {
+		return 0, err
+	}
+	//Half of the round-trip time is used as the moment when the meter read the time.
+	now := start.Add(time.Since(start) / 2)
+	return clock.Time.Value.Sub(now), nil
+}
+
+// SyncTime corrects the meter clock if the drift exceeds maxDrift.
+// Small drifts are corrected with shift_time method so billing periods are not broken.
+// The time is written if the drift is too big to shift or the meter doesn't support shifting.
+func (r *GXDLMSReader) SyncTime(clock *objects.GXDLMSClock, maxDrift time.Duration) error {
+	drift, err := r.ClockDrift(clock)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Meter time: %s drift: %s\n", clock.Time.String(), drift.Round(time.Millisecond))
+	if drift.Abs() <= maxDrift {
+		fmt.Fprintln(os.Stderr, "Clock is in sync.")
+		return nil
+	}
+	shifted := false
+	if drift.Abs() <= maxShiftTime && r.client.CanInvoke(clock, 6) {
+		seconds := int16(-drift.Round(time.Second) / time.Second)
+		if err = r.Method(clock, 6, seconds); err != nil {
+			fmt.Fprintf(os.Stderr, "Shift time failed: %v. Time is written.\n", err)
+		} else {
+			shifted = true
+		}
+	}
+	if !shifted {
+		clock.Time = *types.NewGXDateTimeFromTime(time.Now())
+		if err = r.Write(clock, 2); err != nil {
+			return err
+		}
+	}
+	drift, err = r.ClockDrift(clock)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Meter time: %s drift after sync: %s\n", clock.Time.String(), drift.Round(time.Millisecond))
+	return nil
+}
+
+// deviationNotSpecified is the deviation when the meter doesn't tell the UTC offset.
+const deviationNotSpecified = -32768
+
+// clockStatusNames are the names of the clock status flags in the shown date-time.
+var clockStatusNames = []struct {
+	Status enums.ClockStatus
+	Name   string
+}{
+	{enums.ClockStatusInvalidValue, "invalid"},
+	{enums.ClockStatusDoubtfulValue, "questionable"},
+	{enums.ClockStatusDifferentClockBase, "different clock base"},
+	{enums.ClockStatusInvalidClockStatus, "invalid status"},
+	{enums.ClockStatusDaylightSavingActive, "DST"},
+}
+
+// formatDateTime returns the date-time in the meter local time with the UTC offset and the clock status flags.
+// Ex. 2026-10-16 12:00:00 UTC+03:00 [DST].
+// DLMS deviation is the difference from local time to UTC. It's the UTC offset if UseUtc2NormalTime is set.
+func (r *GXDLMSReader) formatDateTime(v *types.GXDateTime) string {
+	var sb strings.Builder
+	if v.Deviation == deviationNotSpecified {
+		sb.WriteString(v.Value.Format(time.DateTime))
+	} else {
+		offset := -v.Deviation
+		if r.client.UseUtc2NormalTime() {
+			offset = v.Deviation
+		}
+		sb.WriteString(v.Value.In(time.FixedZone("", offset*60)).Format(time.DateTime))
+		sign := '+'
+		if offset < 0 {
+			sign = '-'
+			offset = -offset
+		}
+		fmt.Fprintf(&sb, " UTC%c%02d:%02d", sign, offset/60, offset%60)
+	}
+	if v.Status != enums.ClockStatusSkip {
+		for _, it := range clockStatusNames {
+			if v.Status&it.Status != 0 {
+				sb.WriteString(" [" + it.Name + "]")
+			}
+		}
+	}
+	return sb.String()
+}
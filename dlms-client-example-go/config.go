@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// gxConfig is the JSON configuration file. Each field is converted to the command line flag
+// in the flag tag, so the file accepts the same values as the flags.
+type gxConfig struct {
+	Host                 string   `json:"host" flag:"h" help:"Meter IP address."`
+	Port                 int      `json:"port" flag:"p" help:"Meter port number."`
+	Interface            string   `json:"interface" flag:"i" help:"Interface type. HDLC, WRAPPER, HdlcWithModeE, Plc or PlcHdlc."`
+	Serial               string   `json:"serial" flag:"S" help:"Serial port. Ex. COM1:9600:8None1."`
+	ClientAddress        int      `json:"clientAddress" flag:"c" help:"Client address."`
+	ServerAddress        int      `json:"serverAddress" flag:"s" help:"Server address."`
+	LogicalServerAddress int      `json:"logicalServerAddress" flag:"l" help:"Logical server address."`
+	Authentication       string   `json:"authentication" flag:"a" help:"Authentication. None, Low or High."`
+	Password             string   `json:"password" flag:"P" help:"Password for authentication."`
+	Security             string   `json:"security" flag:"C" help:"Security level. Authentication, Encryption or AuthenticationEncryption."`
+	SecuritySuite        string   `json:"securitySuite" flag:"V" help:"Security suite. Suite0, Suite1 or Suite2."`
+	SystemTitle          string   `json:"systemTitle" flag:"T" help:"Client system title as hex."`
+	ServerSystemTitle    string   `json:"serverSystemTitle" flag:"M" help:"Meter system title as hex."`
+	AuthenticationKey    string   `json:"authenticationKey" flag:"A" help:"Authentication key as hex."`
+	BlockCipherKey       string   `json:"blockCipherKey" flag:"B" help:"Block cipher key as hex."`
+	DedicatedKey         string   `json:"dedicatedKey" flag:"D" help:"Dedicated key as hex."`
+	InvocationCounter    string   `json:"invocationCounter" flag:"v" help:"Invocation counter logical name."`
+	Objects              []string `json:"objects" flag:"g" help:"Read objects. Ex. [\"0.0.1.0.0.255:2\"]."`
+	CacheFile            string   `json:"cacheFile" flag:"o" help:"Association view cache file."`
+	Trace                string   `json:"trace" flag:"t" help:"Trace level. Off, Error, Warning, Info or Verbose."`
+	WaitTime             int      `json:"waitTime" flag:"x" help:"Reply wait time in milliseconds."`
+	AutoIncreaseInvokeID bool     `json:"autoIncreaseInvokeId" flag:"I" help:"Auto increase invoke ID."`
+	RefreshCache         bool     `json:"refreshCache" flag:"refresh-cache" help:"Read the association view from the meter and rewrite the cache file."`
+	BestEffort           bool     `json:"bestEffort" flag:"best-effort" help:"Read the received objects if the association view is read only partially."`
+	StrictTitle          bool     `json:"strictTitle" flag:"strict-title" help:"Connection fails if the meter system title is not the server system title."`
+	NoProfiles           bool     `json:"noProfiles" flag:"no-profiles" help:"Don't read profile generic rows."`
+}
+
+// expandConfig replaces -config file with the flags that are read from the file.
+// Flags that are given in the command line are not read from the file, so the command line overrides them.
+func expandConfig(args []string) ([]string, error) {
+	for pos, it := range args {
+		if it != "-config" && it != "--config" {
+			continue
+		}
+		if pos+1 >= len(args) {
+			return nil, fmt.Errorf("flag %s requires a value", it)
+		}
+		data, err := os.ReadFile(args[pos+1])
+		if err != nil {
+			return nil, err
+		}
+		var cfg gxConfig
+		if err = json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", args[pos+1], err)
+		}
+		ret := cfg.toArgs(commandLineFlags(args))
+		ret = append(ret, args[:pos]...)
+		return append(ret, args[pos+2:]...), nil
+	}
+	return args, nil
+}
+
+// commandLineFlags returns the names of the flags that are given in the command line.
+func commandLineFlags(args []string) map[string]bool {
+	flags := map[string]bool{}
+	for _, it := range args {
+		if len(it) > 1 && it[0] == '-' {
+			flags[strings.TrimPrefix(it[1:], "-")] = true
+		}
+	}
+	return flags
+}
+
+// toArgs converts the configuration to command line flags. Empty values and the flags
+// that are given in the command line are skipped. Boolean flags are added without a value.
+func (c *gxConfig) toArgs(skip map[string]bool) []string {
+	var args []string
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+		flag := t.Field(i).Tag.Get("flag")
+		if f.IsZero() || skip[flag] {
+			continue
+		}
+		var value string
+		switch f.Kind() {
+		case reflect.Bool:
+			args = append(args, "-"+flag)
+			continue
+		case reflect.String:
+			value = f.String()
+		case reflect.Int:
+			value = strconv.FormatInt(f.Int(), 10)
+		case reflect.Slice:
+			value = strings.Join(f.Interface().([]string), ";")
+		}
+		args = append(args, "-"+flag, value)
+	}
+	return args
+}
+
+// showConfigHelp shows the fields of the configuration file.
+func showConfigHelp() {
+	fmt.Println("Config file (-config meter.json) fields. Command line flags override the values in the file.")
+	t := reflect.TypeFor[gxConfig]()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fmt.Printf("  %s (-%s) \t %s\n", f.Tag.Get("json"), f.Tag.Get("flag"), f.Tag.Get("help"))
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// DryRun generates SNRM, AARQ, read and release frames from the client settings and writes them as hex.
+// Nothing is sent and the media is not opened.
+// Objects are searched from the association view cache. Unknown objects are read as Data objects.
+func (r *GXDLMSReader) DryRun(w io.Writer, readObjects []*types.GXKeyValuePair[string, int], cacheFile string) error {
+	if cacheFile != "" {
+		if _, err := os.Stat(cacheFile); err == nil {
+			if err = r.client.Objects().LoadFromFile(cacheFile); err != nil {
+				return err
+			}
+		}
+	}
+	show := func(name string, frames ...[]byte) {
+		for _, it := range frames {
+			fmt.Fprintf(w, "%s: %s\n", name, types.ToHex(it, true))
+		}
+	}
+	frame, err := r.client.SNRMRequest()
+	if err != nil {
+		return err
+	}
+	if frame != nil {
+		show("SNRM", frame)
+	}
+	if !r.client.PreEstablishedConnection() {
+		frames, err := r.client.AARQRequest()
+		if err != nil {
+			return err
+		}
+		show("AARQ", frames...)
+		if r.client.Authentication() > enums.AuthenticationLow {
+			//HLS reply to the server challenge needs AARE from the meter.
+			fmt.Fprintln(w, "HLS application association is skipped. It needs the challenge from the meter.")
+		}
+	}
+	for _, it := range readObjects {
+		obj := r.client.Objects().FindByLN(enums.ObjectTypeNone, it.Key)
+		if obj == nil {
+			d, err := objects.NewGXDLMSData(it.Key, 0)
+			if err != nil {
+				return err
+			}
+			obj = d
+		}
+		frames, err := r.client.Read(obj, it.Value)
+		if err != nil {
+			return fmt.Errorf("read %s:%d failed: %w", it.Key, it.Value, err)
+		}
+		show(fmt.Sprintf("Read %s:%d", it.Key, it.Value), frames...)
+	}
+	if r.client.InterfaceType() == enums.InterfaceTypeWRAPPER ||
+		(r.client.Ciphering().Security() != enums.SecurityNone && !r.client.PreEstablishedConnection()) {
+		frames, err := r.client.ReleaseRequest()
+		if err != nil {
+			return err
+		}
+		show("Release", frames...)
+	}
+	frame, err = r.client.DisconnectRequest()
+	if err != nil {
+		return err
+	}
+	if frame != nil {
+		show("Disconnect", frame)
+	}
+	return nil
+}
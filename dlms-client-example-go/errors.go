@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// ErrCertificateGeneration is returned when the meter can't generate its own key pair.
+var ErrCertificateGeneration = errors.New("meter does not support certificate generation")
+
+// ErrReceiveTimeout is returned when the meter doesn't reply after all retries.
+var ErrReceiveTimeout = errors.New("failed to receive reply from the device in given time")
+
+// ErrAssociationFailed is returned when SNRM or AARQ fails. The reason is wrapped in the same error.
+var ErrAssociationFailed = errors.New("association failed")
+
+// ErrObjectNil is returned when the object is nil.
+var ErrObjectNil = errors.New("object is nil")
+
+// ErrCannotRead is returned when the attribute is not readable with the current association.
+var ErrCannotRead = errors.New("cannot read")
+
+// ErrCannotWrite is returned when the attribute is not writable with the current association.
+var ErrCannotWrite = errors.New("cannot write")
+
+// ErrCannotInvoke is returned when the method can't be invoked with the current association.
+var ErrCannotInvoke = errors.New("cannot invoke")
+
+// GXCipherError is returned when a ciphered reply can't be decrypted or authenticated.
+type GXCipherError struct {
+	Err error
+}
+
+func (e *GXCipherError) Error() string {
+	return "decrypting the reply failed: " + e.Err.Error() +
+		". Check block cipher/authentication keys and frame counter"
+}
+
+// Unwrap returns the error that the library returned.
+func (e *GXCipherError) Unwrap() error {
+	return e.Err
+}
+
+// GXConformanceError is returned when the operation needs a service that the meter didn't negotiate.
+type GXConformanceError struct {
+	Conformance enums.Conformance
+}
+
+func (e *GXConformanceError) Error() string {
+	return "meter did not negotiate " + e.Conformance.String()
+}
+
+// GXSystemTitleError is returned when the meter system title is not the expected one.
+type GXSystemTitleError struct {
+	Expected []byte
+	Actual   []byte
+}
+
+func (e *GXSystemTitleError) Error() string {
+	return "meter system title " + types.ToHex(e.Actual, false) + " is not the expected " + types.ToHex(e.Expected, false)
+}
+
+// GXDLMSError is returned when the meter replies with the DLMS error code.
+// errors.Is can be used with the error code, e.g. errors.Is(err, enums.ErrorCodeReadWriteDenied).
+type GXDLMSError struct {
+	Code enums.ErrorCode
+}
+
+func (e *GXDLMSError) Error() string {
+	return e.Code.Error()
+}
+
+// Unwrap returns the DLMS error code.
+func (e *GXDLMSError) Unwrap() error {
+	return e.Code
+}
+
+// Permanent returns true if the request fails again when it's resent, e.g. access is denied
+// or the object is unavailable. Busy meter and aborted block transfers are transient.
+func (e *GXDLMSError) Permanent() bool {
+	switch e.Code {
+	case enums.ErrorCodeTemporaryFailure, enums.ErrorCodeRejected,
+		enums.ErrorCodeLongGetOrReadAborted, enums.ErrorCodeLongSetOrWriteAborted:
+		return false
+	default:
+		return true
+	}
+}
+
+// isRetryable returns true if the meter was busy and the same request may succeed later.
+func isRetryable(err error) bool {
+	return errors.Is(err, enums.ErrorCodeTemporaryFailure) || errors.Is(err, enums.ErrorCodeRejected)
+}
+
+// GXAssociationRejectedError is returned when the meter rejects the AARQ.
+type GXAssociationRejectedError struct {
+	Err error
+}
+
+func (e *GXAssociationRejectedError) Error() string {
+	return "meter rejected the association: " + e.Err.Error()
+}
+
+// Unwrap returns the error that the library returned.
+func (e *GXAssociationRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// isReferencingError returns true if the meter rejected the association. The rejection reason
+// is not separated from the other AARE errors, so the other referencing is tried once for all of them.
+func isReferencingError(err error) bool {
+	var rejected *GXAssociationRejectedError
+	return errors.As(err, &rejected)
+}
+
+// isLinkError returns true if err is caused by the lost connection to the meter.
+func isLinkError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, ErrReceiveTimeout) ||
+		errors.Is(err, gxcommon.ErrConnectionClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.As(err, &netErr)
+}
+
+// errAuthenticationFailed is returned by AES-GCM when the authentication tag (GMAC) of the ciphered
+// APDU doesn't match. The error is not exported, so it's taken from the failed open with 12 byte tag that DLMS uses.
+var errAuthenticationFailed = func() error {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCMWithTagSize(block, 12)
+	if err != nil {
+		return err
+	}
+	_, err = aead.Open(nil, make([]byte, aead.NonceSize()), make([]byte, aead.Overhead()), nil)
+	return err
+}()
+
+// isCipherError returns true if the ciphered reply can't be decrypted or the authentication tag check fails.
+// Framing and parsing errors are not cipher errors even if the security is used.
+func isCipherError(err error, security enums.Security) bool {
+	return security != enums.SecurityNone && errors.Is(err, errAuthenticationFailed)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	dlms "github.com/Gurux/gxdlms-go"
+	"github.com/Gurux/gxdlms-go/enums"
+)
+
+func TestIsCipherError(t *testing.T) {
+	if errAuthenticationFailed == nil {
+		t.Fatal("AES-GCM accepted the invalid authentication tag")
+	}
+	tests := []struct {
+		name     string
+		err      error
+		security enums.Security
+		expected bool
+	}{
+		{"authentication tag check fails", errAuthenticationFailed, enums.SecurityAuthenticationEncryption, true},
+		{"wrapped authentication failure", fmt.Errorf("decrypt: %w", errAuthenticationFailed), enums.SecurityAuthentication, true},
+		{"security is not used", errAuthenticationFailed, enums.SecurityNone, false},
+		{"invalid frame", errors.New("invalid checksum"), enums.SecurityAuthenticationEncryption, false},
+		{"meter error", &GXDLMSError{Code: enums.ErrorCodeReadWriteDenied}, enums.SecurityAuthenticationEncryption, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCipherError(tt.err, tt.security); got != tt.expected {
+				t.Errorf("returned %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// Corrupted frame is a framing error even if the reply is ciphered.
+func TestReadDLMSPacketCorruptedFrameIsNotCipherError(t *testing.T) {
+	//Glo-get-response with the invocation counter 1, empty ciphered content and the authentication tag.
+	ciphered := []byte{0xCC, 0x11, 0x30, 0x00, 0x00, 0x00, 0x01,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C}
+	frame := hdlcFrame(0x30, ciphered, true)
+	//Last byte of the information field is changed so the FCS doesn't match.
+	frame[len(frame)-4] ^= 0xFF
+	media := NewGXMockMedia(
+		GXMockExchange{RX: hdlcFrame(0x73, testUA, false)},
+		GXMockExchange{RX: frame})
+	r := newTestReader(t, media, enums.InterfaceTypeHDLC)
+	if err := r.SNRMRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.client.SetSecurity(enums.SecurityAuthenticationEncryption); err != nil {
+		t.Fatal(err)
+	}
+	err := r.ReadDLMSPacket([]byte{0x7E}, dlms.NewGXReplyData())
+	if err == nil {
+		t.Fatal("corrupted frame was accepted")
+	}
+	var cipherErr *GXCipherError
+	if errors.As(err, &cipherErr) {
+		t.Errorf("corrupted frame was reported as cipher error: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+)
+
+// idisEventCodes are the event codes that DLMS and IDIS meters use in the event logs.
+var idisEventCodes = map[int]string{
+	1:  "Power down",
+	2:  "Power up",
+	3:  "Daylight saving time enabled or disabled",
+	4:  "Clock adjusted (old date/time)",
+	5:  "Clock adjusted (new date/time)",
+	6:  "Clock invalid",
+	7:  "Replace battery",
+	8:  "Battery voltage low",
+	9:  "TOU activated",
+	10: "Error register cleared",
+	11: "Alarm register cleared",
+	12: "Program memory error",
+	13: "RAM error",
+	14: "NV memory error",
+	15: "Watchdog error",
+	16: "Measurement system error",
+	17: "Firmware ready for activation",
+	18: "Firmware activated",
+	19: "Passive TOU programmed",
+	20: "External alert detected",
+	40: "Terminal cover removed",
+	41: "Terminal cover closed",
+	42: "Strong DC field detected",
+	43: "No strong DC field anymore",
+	44: "Meter cover removed",
+	45: "Meter cover closed",
+	46: "Association authentication failure",
+	47: "One or more parameters changed",
+	48: "Global key changed",
+	49: "Firmware verification failed",
+	59: "Disconnector ready for manual reconnection",
+	60: "Manual disconnection",
+	61: "Manual connection",
+	62: "Remote disconnection",
+	63: "Remote connection",
+	64: "Local disconnection",
+	65: "Limiter threshold exceeded",
+	66: "Limiter threshold ok",
+	67: "Limiter threshold changed",
+}
+
+// indiaEventCodes are the event codes of the IS 15959 meters.
+var indiaEventCodes = map[int]string{
+	1:   "R-phase PT link missing",
+	2:   "R-phase PT link restored",
+	3:   "Y-phase PT link missing",
+	4:   "Y-phase PT link restored",
+	5:   "B-phase PT link missing",
+	6:   "B-phase PT link restored",
+	7:   "Over voltage",
+	8:   "Over voltage restored",
+	9:   "Low voltage",
+	10:  "Low voltage restored",
+	11:  "Voltage unbalance",
+	12:  "Voltage unbalance restored",
+	51:  "Phase R CT reverse",
+	52:  "Phase R CT reverse restored",
+	53:  "Phase Y CT reverse",
+	54:  "Phase Y CT reverse restored",
+	55:  "Phase B CT reverse",
+	56:  "Phase B CT reverse restored",
+	67:  "Over current",
+	68:  "Over current restored",
+	101: "Power failure",
+	102: "Power restored",
+	151: "Real time clock changed",
+	152: "Demand integration period changed",
+	153: "Profile capture period changed",
+	154: "Single action schedule for billing dates changed",
+	155: "Activity calendar for time zones changed",
+	201: "Magnetic influence",
+	202: "Magnetic influence restored",
+	203: "Neutral disturbance",
+	204: "Neutral disturbance restored",
+	251: "Meter cover opened",
+}
+
+// eventDescription returns the description of the event code in the standard.
+func eventDescription(standard enums.Standard, code int) string {
+	codes := idisEventCodes
+	if standard == enums.StandardIndia {
+		codes = indiaEventCodes
+	}
+	if it, ok := codes[code]; ok {
+		return it
+	}
+	return "Unknown event"
+}
+
+// isEventLog returns true if the logical name is an event log (0.b.99.98.e.255).
+func isEventLog(ln string) bool {
+	parts := strings.Split(ln, ".")
+	return len(parts) == 6 && parts[0] == "0" && parts[2] == "99" && parts[3] == "98"
+}
+
+// isEventCode returns true if the logical name is an event code object (0.b.96.11.e.255).
+func isEventCode(ln string) bool {
+	parts := strings.Split(ln, ".")
+	return len(parts) == 6 && parts[0] == "0" && parts[2] == "96" && parts[3] == "11"
+}
+
+// ReadEvents reads all event logs and writes the entries with the decoded event codes.
+func (r *GXDLMSReader) ReadEvents(w io.Writer) error {
+	standard := r.client.Standard()
+	found := false
+	for _, it := range r.client.Objects().GetObjects(enums.ObjectTypeProfileGeneric) {
+		pg, ok := it.(*objects.GXDLMSProfileGeneric)
+		if !ok || !isEventLog(pg.Base().LogicalName()) {
+			continue
+		}
+		found = true
+		ln := pg.Base().LogicalName()
+		if len(pg.CaptureObjects) == 0 {
+			if _, err := r.Read(pg, 3); err != nil {
+				return err
+			}
+		}
+		rows, err := r.ReadRowsByEntry(pg, 1, 0)
+		if err != nil {
+			fmt.Fprintf(w, "%s: read failed: %v\n", ln, err)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %d events\n", ln, len(rows))
+		for _, row := range rows {
+			cells := make([]string, 0, len(row))
+			for pos, cell := range row {
+				value := r.csvValue(cell)
+				if pos < len(pg.CaptureObjects) && isEventCode(pg.CaptureObjects[pos].Key.Base().LogicalName()) {
+					if code, ok := toFloat(cell); ok {
+						value = fmt.Sprintf("%s (%s)", value, eventDescription(standard, int(code)))
+					}
+				}
+				cells = append(cells, value)
+			}
+			fmt.Fprintf(w, "  %s\n", strings.Join(cells, " "))
+		}
+	}
+	if !found {
+		return errors.New("event logs not found")
+	}
+	return nil
+}
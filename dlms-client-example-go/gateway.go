@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/settings"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// gatewayRequest is the tag of the DLMS gateway request PDU.
+const gatewayRequest = 0xE6
+
+// parseGateway parses the gateway network ID and the hex physical device address. Ex. 0:1.
+// Chain of gateways (0:1,2:3) is recognized, but the gateway request PDU has room for one hop only.
+func parseGateway(value string) (*settings.GXDLMSGateway, error) {
+	hops := strings.Split(value, ",")
+	if len(hops) > 1 {
+		return nil, fmt.Errorf("invalid -G %q. Only one gateway hop is supported, %d hops given", value, len(hops))
+	}
+	id, address, ok := strings.Cut(hops[0], ":")
+	if !ok || address == "" {
+		return nil, fmt.Errorf("invalid -G %q. Use NetworkId:PhysicalDeviceAddress", value)
+	}
+	ret, err := strconv.ParseUint(id, 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -G network id %q", id)
+	}
+	return &settings.GXDLMSGateway{NetworkID: uint8(ret), PhysicalDeviceAddress: types.HexToBytes(address)}, nil
+}
+
+// validateGateway checks that the gateway request can be sent with the interface type.
+// Gateway PDU is added before the APDU and the interface must carry the APDU as it is.
+func validateGateway(gw *settings.GXDLMSGateway, interfaceType enums.InterfaceType) error {
+	if gw == nil {
+		return nil
+	}
+	switch interfaceType {
+	case enums.InterfaceTypeHDLC, enums.InterfaceTypeHdlcWithModeE, enums.InterfaceTypeWRAPPER:
+		return nil
+	default:
+		return fmt.Errorf("-G can't be used with interface type %s. Use HDLC or WRAPPER", interfaceType.String())
+	}
+}
+
+// gatewayHeader returns the bytes that are prepended to the APDU when the gateway is used.
+func gatewayHeader(gw *settings.GXDLMSGateway) []byte {
+	header := []byte{gatewayRequest, gw.NetworkID, byte(len(gw.PhysicalDeviceAddress))}
+	return append(header, gw.PhysicalDeviceAddress...)
+}
+
+// logGateway logs the gateway header of the first APDU frame.
+// Warning is logged if the frame is sent without the gateway header.
+func (r *GXDLMSReader) logGateway(frame []byte) {
+	gw := r.client.Gateway()
+	if gw == nil {
+		return
+	}
+	args := []any{"networkId", gw.NetworkID, "physicalDeviceAddress", types.ToHex(gw.PhysicalDeviceAddress, false)}
+	if !bytes.Contains(frame, gatewayHeader(gw)) {
+		r.logger.Warn("gateway header is missing from the frame", args...)
+		return
+	}
+	r.logger.Debug("gateway", args...)
+}
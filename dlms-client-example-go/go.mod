@@ -10,6 +10,14 @@ require github.com/Gurux/gxserial-go v1.0.11
 
 require github.com/Gurux/gxdlms-go v1.0.16
 
+require github.com/ThalesGroup/crypto11 v1.6.0
+
+require (
+	github.com/miekg/pkcs11 v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/thales-e-security/pool v0.0.2 // indirect
+)
+
 require golang.org/x/text v0.36.0 // indirect
 
 replace github.com/Gurux/gxdlms-go => ../../gxdlms-go
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// hexDumpTimeFormat is the time stamp format of the hex dump.
+	hexDumpTimeFormat = "2006-01-02 15:04:05.000000"
+	// text2pcapTimeFormat is the matching time stamp format for text2pcap -t.
+	text2pcapTimeFormat = "%Y-%m-%d %H:%M:%S."
+)
+
+// formatHexDump formats the frame so text2pcap can convert it to pcap.
+// The frame starts with the direction (O is sent and I is received) and the time stamp.
+// Data is written as 16 bytes per line with the offset.
+func formatHexDump(sent bool, t time.Time, data []byte) string {
+	var sb strings.Builder
+	direction := "I"
+	if sent {
+		direction = "O"
+	}
+	fmt.Fprintf(&sb, "%s %s\n", direction, t.Format(hexDumpTimeFormat))
+	for pos := 0; pos < len(data); pos += 16 {
+		fmt.Fprintf(&sb, "%06x", pos)
+		for _, b := range data[pos:min(pos+16, len(data))] {
+			fmt.Fprintf(&sb, " %02x", b)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// writeHexDump appends the sent or received frame to the hex dump file.
+// Convert the file to pcap with: text2pcap -D -t "%Y-%m-%d %H:%M:%S." -l 147 trace.hex trace.pcap
+func (r *GXDLMSReader) writeHexDump(sent bool, data []byte) {
+	if r.HexDumpFile == "" || len(data) == 0 {
+		return
+	}
+	f, err := os.OpenFile(r.HexDumpFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			r.logger.Warn("failed to close hex dump file", "error", closeErr)
+		}
+	}()
+	_, _ = f.WriteString(formatHexDump(sent, time.Now(), data))
+}
@@ -0,0 +1,14 @@
+//go:build !pkcs11
+
+package main
+
+import "errors"
+
+// NewGXPkcs11Signer returns an error because PKCS #11 support needs cgo and the crypto11 module.
+// Build with: go build -tags pkcs11
+func NewGXPkcs11Signer(uri string) (GXSigner, error) {
+	if _, err := parsePkcs11URI(uri); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("-hsm needs PKCS #11 support. Build with -tags pkcs11")
+}
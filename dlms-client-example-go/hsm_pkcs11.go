@@ -0,0 +1,71 @@
+//go:build pkcs11
+
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/ThalesGroup/crypto11"
+)
+
+// GXPkcs11Signer signs with the key that is stored in the PKCS #11 token.
+// The private key never leaves the token.
+type GXPkcs11Signer struct {
+	ctx    *crypto11.Context
+	signer crypto11.Signer
+	public *ecdsa.PublicKey
+}
+
+// NewGXPkcs11Signer opens the PKCS #11 module and finds the signing key with the URI.
+func NewGXPkcs11Signer(uri string) (GXSigner, error) {
+	u, err := parsePkcs11URI(uri)
+	if err != nil {
+		return nil, err
+	}
+	config := &crypto11.Config{Path: u.ModulePath, TokenLabel: u.Token, Pin: u.Pin}
+	if u.Token == "" {
+		//Token is not given in the URI. The first slot is used.
+		config.SlotNumber = new(int)
+	}
+	ctx, err := crypto11.Configure(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", u.ModulePath, err)
+	}
+	var label []byte
+	if u.Object != "" {
+		label = []byte(u.Object)
+	}
+	signer, err := ctx.FindKeyPair(u.ID, label)
+	if err == nil && signer == nil {
+		err = errors.New("key not found")
+	}
+	if err != nil {
+		ctx.Close()
+		return nil, fmt.Errorf("%s: %w", uri, err)
+	}
+	public, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		ctx.Close()
+		return nil, fmt.Errorf("%s: key is not an elliptic key", uri)
+	}
+	return &GXPkcs11Signer{ctx: ctx, signer: signer, public: public}, nil
+}
+
+// Public returns the public key of the signing key.
+func (s *GXPkcs11Signer) Public() *ecdsa.PublicKey {
+	return s.public
+}
+
+// Sign returns the ASN.1 encoded ECDSA signature of the digest.
+func (s *GXPkcs11Signer) Sign(digest []byte) ([]byte, error) {
+	return s.signer.Sign(rand.Reader, digest, crypto.Hash(0))
+}
+
+// Close closes the PKCS #11 session.
+func (s *GXPkcs11Signer) Close() error {
+	return s.ctx.Close()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	dlms "github.com/Gurux/gxdlms-go"
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// GXTraceFrame is one frame that is sent to or received from the meter.
+type GXTraceFrame struct {
+	Time time.Time
+	Sent bool
+	Data []byte
+}
+
+type htmlReportFrame struct {
+	Time      string
+	Direction string
+	Hex       string
+	Xml       string
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>DLMS session report</title>
+<style>
+body { font-family: sans-serif; }
+details { margin: 4px 0; padding: 4px; border-left: 4px solid; }
+details.TX { border-color: #1f77b4; background: #eef5fb; }
+details.RX { border-color: #2ca02c; background: #eef8ee; }
+pre { white-space: pre-wrap; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>DLMS session report</h1>
+<p>Created {{.Created}}. Frames: {{len .Frames}}.</p>
+{{range .Frames}}<details class="{{.Direction}}">
+<summary>{{.Time}} {{.Direction}}</summary>
+<pre>{{.Hex}}</pre>
+<pre>{{.Xml}}</pre>
+</details>
+{{end}}</body>
+</html>
+`))
+
+// recordFrame stores the sent or received frame if frames are recorded.
+func (r *GXDLMSReader) recordFrame(sent bool, data []byte) {
+	r.Stats.addFrame(sent, len(data))
+	if !r.RecordFrames || len(data) == 0 {
+		return
+	}
+	if r.secrets == nil {
+		r.secrets = secretReplacer(r.client)
+	}
+	r.frames = append(r.frames, GXTraceFrame{Time: time.Now(), Sent: sent, Data: append([]byte(nil), data...)})
+}
+
+// Frames returns the recorded frames.
+func (r *GXDLMSReader) Frames() []GXTraceFrame {
+	return r.frames
+}
+
+// SaveHTMLReport saves recorded frames with the decoded XML to the HTML file.
+// Password and keys are removed from the report.
+func (r *GXDLMSReader) SaveHTMLReport(path string) error {
+	translator := dlms.NewGXDLMSTranslator(enums.TranslatorOutputTypeSimpleXml)
+	redact := r.secrets
+	if redact == nil {
+		redact = strings.NewReplacer()
+	}
+	frames := make([]htmlReportFrame, 0, len(r.frames))
+	for _, it := range r.frames {
+		xml, err := translator.MessageToXml(it.Data)
+		if err != nil {
+			xml = "Failed to decode the frame: " + err.Error()
+		}
+		direction := "RX"
+		if it.Sent {
+			direction = "TX"
+		}
+		frames = append(frames, htmlReportFrame{
+			Time:      it.Time.Format("15:04:05.000"),
+			Direction: direction,
+			Hex:       redact.Replace(types.ToHex(it.Data, true)),
+			Xml:       redact.Replace(xml),
+		})
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = htmlReportTemplate.Execute(f, map[string]any{
+		"Created": time.Now().Format(time.RFC3339),
+		"Frames":  frames,
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// secretReplacer returns a replacer that hides the password and keys from the hex and XML output.
+func secretReplacer(client *dlms.GXDLMSSecureClient) *strings.Replacer {
+	var pairs []string
+	c := client.Ciphering()
+	for _, it := range [][]byte{
+		client.Password(),
+		c.AuthenticationKey(),
+		c.BlockCipherKey(),
+		c.BroadcastBlockCipherKey(),
+		c.DedicatedKey(),
+	} {
+		if len(it) == 0 {
+			continue
+		}
+		pairs = append(pairs, types.ToHex(it, true), "<redacted>", types.ToHex(it, false), "<redacted>")
+	}
+	return strings.NewReplacer(pairs...)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// invocationCounterMargin is added to the stored invocation counter. It covers the frames that were sent
+// after the counter was saved, e.g. if the application was killed in the middle of the read.
+const invocationCounterMargin = 100
+
+// GXInvocationCounterStore saves the last used invocation counters to the JSON file.
+// Counters are stored by the meter system title, or by the media name if the system title is not given.
+type GXInvocationCounterStore struct {
+	// Path is the JSON file.
+	Path string
+
+	mu sync.Mutex
+}
+
+var (
+	icStoresMu sync.Mutex
+	icStores   = map[string]*GXInvocationCounterStore{}
+)
+
+// NewGXInvocationCounterStore returns the invocation counter store of the file.
+// Same store is returned for the same file, so parallel readers don't overwrite the counters of each other.
+func NewGXInvocationCounterStore(path string) *GXInvocationCounterStore {
+	key := path
+	if abs, err := filepath.Abs(path); err == nil {
+		key = abs
+	}
+	icStoresMu.Lock()
+	defer icStoresMu.Unlock()
+	s, ok := icStores[key]
+	if !ok {
+		s = &GXInvocationCounterStore{Path: path}
+		icStores[key] = s
+	}
+	return s
+}
+
+// Load returns the stored invocation counter of the meter. False is returned if it's not stored.
+func (s *GXInvocationCounterStore) Load(key string) (uint32, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counters, err := s.read()
+	if err != nil {
+		return 0, false, err
+	}
+	ic, ok := counters[key]
+	return ic, ok, nil
+}
+
+// Save stores the invocation counter of the meter. Counter is never decreased.
+func (s *GXInvocationCounterStore) Save(key string, ic uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counters, err := s.read()
+	if err != nil {
+		return err
+	}
+	if counters[key] >= ic {
+		return nil
+	}
+	counters[key] = ic
+	data, err := json.MarshalIndent(counters, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.Path)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	//File is replaced at once so the counters are not lost if writing fails.
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// read reads all counters. Empty map is returned if the file doesn't exist.
+func (s *GXInvocationCounterStore) read() (map[string]uint32, error) {
+	counters := map[string]uint32{}
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return counters, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &counters); err != nil {
+		return nil, err
+	}
+	return counters, nil
+}
+
+// invocationCounterKey returns the key of the meter in the invocation counter store.
+func (r *GXDLMSReader) invocationCounterKey() string {
+	if title := r.client.Ciphering().RecipientSystemTitle(); len(title) != 0 {
+		return types.ToHex(title, false)
+	}
+	return r.media.GetName()
+}
+
+// loadInvocationCounter sets the stored invocation counter with the margin to the ciphering.
+// Counter is not decreased if it's already larger, e.g. it's read from the meter with -v.
+func (r *GXDLMSReader) loadInvocationCounter() error {
+	if r.ICStore == nil || r.client.Ciphering().Security() == enums.SecurityNone {
+		return nil
+	}
+	ic, ok, err := r.ICStore.Load(r.invocationCounterKey())
+	if err != nil || !ok {
+		return err
+	}
+	ic += invocationCounterMargin
+	if ic <= r.client.Ciphering().InvocationCounter() {
+		return nil
+	}
+	r.logger.Debug("invocation counter loaded", "file", r.ICStore.Path, "value", ic)
+	return r.client.Ciphering().SetInvocationCounter(ic)
+}
+
+// saveInvocationCounter stores the current invocation counter.
+func (r *GXDLMSReader) saveInvocationCounter() {
+	if r.ICStore == nil || r.client.Ciphering().Security() == enums.SecurityNone {
+		return
+	}
+	if err := r.ICStore.Save(r.invocationCounterKey(), r.client.Ciphering().InvocationCounter()); err != nil {
+		r.logger.Warn("failed to save invocation counter", "file", r.ICStore.Path, "error", err)
+	}
+}
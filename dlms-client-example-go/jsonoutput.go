@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// GXJSONObject is one COSEM object in the JSON output.
+type GXJSONObject struct {
+	LogicalName string            `json:"logicalName"`
+	ObjectType  string            `json:"objectType"`
+	Attributes  []GXJSONAttribute `json:"attributes"`
+	// Columns are the captured columns of the profile generic.
+	Columns []GXJSONColumn `json:"columns,omitempty"`
+}
+
+// GXJSONColumn is one captured column of the profile generic in the JSON output.
+// Buffer values are already multiplied by the scaler.
+type GXJSONColumn struct {
+	LogicalName    string  `json:"logicalName"`
+	AttributeIndex int     `json:"attributeIndex"`
+	Scaler         float64 `json:"scaler"`
+	Unit           string  `json:"unit,omitempty"`
+}
+
+// GXJSONAttribute is one read attribute value in the JSON output.
+type GXJSONAttribute struct {
+	Index int `json:"index"`
+	Value any `json:"value"`
+}
+
+// GXJSONRead is one read attribute value that is written as JSON line.
+type GXJSONRead struct {
+	LogicalName string `json:"logicalName"`
+	ObjectType  string `json:"objectType"`
+	Index       int    `json:"index"`
+	Value       any    `json:"value"`
+	// ScaledValue is the numeric register value. The library has already multiplied it by the scaler.
+	// It's omitted if the scaler is not read.
+	ScaledValue *float64 `json:"scaledValue,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+}
+
+// toJSONRead converts the read attribute value to JSON line.
+func toJSONRead(obj objects.IGXDLMSBase, index int, value any) GXJSONRead {
+	ret := GXJSONRead{
+		LogicalName: obj.Base().LogicalName(),
+		ObjectType:  obj.Base().ObjectType().String(),
+		Index:       index,
+		Value:       jsonValue(value),
+	}
+	if unit, ok := objectUnit(obj, index); ok {
+		if unit != enums.UnitNone {
+			ret.Unit = unit.String()
+		}
+		if v, ok := toFloat(value); ok && objectScaler(obj) != 0 {
+			ret.ScaledValue = &v
+		}
+	}
+	return ret
+}
+
+// GXJSONViewObject is one object of the association view in the JSON dump.
+type GXJSONViewObject struct {
+	LogicalName string                `json:"logicalName"`
+	ShortName   uint16                `json:"shortName,omitempty"`
+	ObjectType  string                `json:"objectType"`
+	Version     uint8                 `json:"version"`
+	Description string                `json:"description,omitempty"`
+	Attributes  []GXJSONViewAttribute `json:"attributes"`
+	Methods     []GXJSONViewMethod    `json:"methods"`
+}
+
+// GXJSONViewAttribute is the access right and the data type of one attribute.
+type GXJSONViewAttribute struct {
+	Index    int    `json:"index"`
+	Access   string `json:"access"`
+	DataType string `json:"dataType,omitempty"`
+}
+
+// GXJSONViewMethod is the access right of one method.
+type GXJSONViewMethod struct {
+	Index  int    `json:"index"`
+	Access string `json:"access"`
+}
+
+// SaveAssociationViewJSON saves the structure of the association view as JSON. Object types, versions,
+// access rights and attribute data types are saved. Attribute values are not saved.
+func (r *GXDLMSReader) SaveAssociationViewJSON(path string) error {
+	list := []GXJSONViewObject{}
+	for _, it := range *r.client.Objects() {
+		b := it.Base()
+		obj := GXJSONViewObject{
+			LogicalName: b.LogicalName(),
+			ShortName:   b.ShortName,
+			ObjectType:  b.ObjectType().String(),
+			Version:     b.Version,
+			Description: b.Description,
+			Attributes:  []GXJSONViewAttribute{},
+			Methods:     []GXJSONViewMethod{},
+		}
+		for index := 1; index <= it.GetAttributeCount(); index++ {
+			att := GXJSONViewAttribute{Index: index, Access: b.GetAccess(index).String()}
+			if dt, err := it.GetDataType(index); err == nil && dt != enums.DataTypeNone {
+				att.DataType = dt.String()
+			}
+			obj.Attributes = append(obj.Attributes, att)
+		}
+		for index := 1; index <= it.GetMethodCount(); index++ {
+			obj.Methods = append(obj.Methods, GXJSONViewMethod{Index: index, Access: b.GetMethodAccess(index).String()})
+		}
+		list = append(list, obj)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// GXJSONReadFailure is one failed attribute read in the JSON output.
+type GXJSONReadFailure struct {
+	LogicalName string `json:"logicalName"`
+	ObjectType  string `json:"objectType"`
+	Index       int    `json:"index"`
+	Error       string `json:"error"`
+	// ErrorCode is the DLMS error code if the meter returned one.
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+// SaveFailuresJSON saves the attributes that the last ReadAll failed to read as JSON.
+func (r *GXDLMSReader) SaveFailuresJSON(path string) error {
+	list := []GXJSONReadFailure{}
+	for _, it := range r.failures {
+		f := GXJSONReadFailure{
+			LogicalName: it.LogicalName,
+			ObjectType:  it.ObjectType.String(),
+			Index:       it.Index,
+			Error:       it.Err.Error(),
+		}
+		var dlmsErr *GXDLMSError
+		if errors.As(it.Err, &dlmsErr) {
+			f.ErrorCode = dlmsErr.Code.String()
+		}
+		list = append(list, f)
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// SaveObjectsJSON saves the objects of the association view and their read attribute values as JSON.
+func (r *GXDLMSReader) SaveObjectsJSON(path string) error {
+	list := []GXJSONObject{}
+	for _, it := range *r.client.Objects() {
+		list = append(list, toJSONObject(it, 0))
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// saveGroupJSON saves the read attribute values of the -g, -gs or -g2 group as JSON.
+func (r *GXDLMSReader) saveGroupJSON(group *gxReadGroup) error {
+	data, err := json.MarshalIndent(r.groupJSON(group), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(group.File, data, 0o644)
+}
+
+// groupJSON returns the read attribute values of the group. Objects that are not in the association view are skipped.
+func (r *GXDLMSReader) groupJSON(group *gxReadGroup) []GXJSONObject {
+	list := []GXJSONObject{}
+	for _, it := range group.Objects {
+		if obj := r.client.Objects().FindByLN(enums.ObjectTypeNone, it.Key); obj != nil {
+			list = append(list, toJSONObject(obj, it.Value))
+		}
+	}
+	for _, it := range group.ShortNames {
+		if obj := r.client.Objects().FindBySN(it.Key); obj != nil {
+			list = append(list, toJSONObject(obj, it.Value))
+		}
+	}
+	for _, it := range group.Selectors {
+		if obj := r.client.Objects().FindByLN(enums.ObjectTypeNone, it.LN); obj != nil {
+			list = append(list, toJSONObject(obj, it.Index))
+		}
+	}
+	return list
+}
+
+// toJSONObject converts the object and the read attribute values to JSON object.
+// Only the given attribute is added if index is not zero.
+func toJSONObject(it objects.IGXDLMSBase, index int) GXJSONObject {
+	obj := GXJSONObject{
+		LogicalName: it.Base().LogicalName(),
+		ObjectType:  it.Base().ObjectType().String(),
+		Attributes:  []GXJSONAttribute{},
+	}
+	//Logical name is not an attribute value.
+	for pos, v := range it.GetValues() {
+		if pos == 0 || v == nil || (index != 0 && pos+1 != index) {
+			continue
+		}
+		obj.Attributes = append(obj.Attributes, GXJSONAttribute{Index: pos + 1, Value: jsonValue(v)})
+	}
+	if pg, ok := it.(*objects.GXDLMSProfileGeneric); ok {
+		for _, c := range profileColumns(pg) {
+			col := GXJSONColumn{LogicalName: c.Target.Base().LogicalName(), AttributeIndex: c.AttributeIndex, Scaler: c.Scaler}
+			if c.Unit != enums.UnitNone {
+				col.Unit = c.Unit.String()
+			}
+			obj.Columns = append(obj.Columns, col)
+		}
+	}
+	return obj
+}
+
+// jsonValue converts the attribute value to the value that can be marshaled to JSON.
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string,
+		int8, int16, int32, int64, int,
+		uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v
+	case []byte:
+		return types.ToHex(v, true)
+	case types.GXDateTime:
+		return v.Value.Format(time.RFC3339)
+	case *types.GXDateTime:
+		return v.Value.Format(time.RFC3339)
+	case types.GXDate:
+		return v.String()
+	case types.GXTime:
+		return v.String()
+	case types.GXArray:
+		return jsonValues(v)
+	case types.GXStructure:
+		return jsonValues(v)
+	case []any:
+		return jsonValues(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jsonValues converts array or structure items to the values that can be marshaled to JSON.
+func jsonValues(items []any) []any {
+	ret := make([]any, 0, len(items))
+	for _, it := range items {
+		ret = append(ret, jsonValue(it))
+	}
+	return ret
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	dlms "github.com/Gurux/gxdlms-go"
+	"github.com/Gurux/gxdlms-go/enums"
+)
+
+// suiteCurve returns the elliptic curve of the security suite.
+func suiteCurve(suite enums.SecuritySuite) (elliptic.Curve, error) {
+	switch suite {
+	case enums.SecuritySuite1:
+		return elliptic.P256(), nil
+	case enums.SecuritySuite2:
+		return elliptic.P384(), nil
+	default:
+		return nil, fmt.Errorf("elliptic keys can't be used with %s. Use -V Suite1 or Suite2", suite.String())
+	}
+}
+
+// loadSuiteKeys loads the client key pair and the certificates from PEM files to the ciphering.
+// Client key is used for signing and key agreement. Signing is done with the HSM key instead if the HSM URI is given.
+// The returned signer must be closed when the ciphering is not used anymore.
+func loadSuiteKeys(c *dlms.GXCiphering, keyFile, certFile, serverCertFile, hsm string) (signer GXSigner, err error) {
+	if keyFile == "" && certFile == "" && serverCertFile == "" && hsm == "" {
+		return nil, nil
+	}
+	curve, err := suiteCurve(c.SecuritySuite())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil && signer != nil {
+			_ = signer.Close()
+			signer = nil
+		}
+	}()
+	var key *ecdsa.PrivateKey
+	if keyFile != "" {
+		if key, err = readPEMKey(keyFile); err != nil {
+			return signer, err
+		}
+		if key.Curve != curve {
+			return signer, fmt.Errorf("%s: %s key can't be used with %s. It needs %s key",
+				keyFile, key.Curve.Params().Name, c.SecuritySuite().String(), curve.Params().Name)
+		}
+		if err = c.SetKeyAgreementKeyPair(key); err != nil {
+			return signer, err
+		}
+		signer = &GXFileSigner{Key: key}
+	}
+	if hsm != "" {
+		if signer, err = NewGXPkcs11Signer(hsm); err != nil {
+			return signer, err
+		}
+		if pub := signer.Public(); pub.Curve != curve {
+			return signer, fmt.Errorf("%s: %s key can't be used with %s. It needs %s key",
+				hsm, pub.Curve.Params().Name, c.SecuritySuite().String(), curve.Params().Name)
+		}
+	}
+	if signer != nil {
+		if err = c.SetSigner(signer.Sign); err != nil {
+			return signer, err
+		}
+	}
+	for _, it := range []string{certFile, serverCertFile} {
+		if it == "" {
+			continue
+		}
+		cert, err := readPEMCertificate(it, curve)
+		if err != nil {
+			return signer, err
+		}
+		if it == certFile && key != nil && !key.PublicKey.Equal(cert.PublicKey) {
+			return signer, fmt.Errorf("%s: certificate doesn't match the client key %s", certFile, keyFile)
+		}
+		if err = c.AddCertificate(cert); err != nil {
+			return signer, err
+		}
+	}
+	return signer, nil
+}
+
+// readPEMKey reads the elliptic private key. Both SEC 1 and PKCS #8 keys are accepted.
+func readPEMKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "EC PRIVATE KEY":
+			key, err := x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return key, nil
+		case "PRIVATE KEY":
+			ret, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			key, ok := ret.(*ecdsa.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("%s: private key is not an elliptic key", path)
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: no private key found", path)
+}
+
+// readPEMCertificate reads the certificate and checks that the public key is on the curve.
+func readPEMCertificate(path string, curve elliptic.Curve) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s: no certificate found", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: certificate public key is not an elliptic key", path)
+	}
+	if pub.Curve != curve {
+		return nil, fmt.Errorf("%s: %s certificate can't be used. It needs %s key", path, pub.Curve.Params().Name, curve.Params().Name)
+	}
+	return cert, nil
+}
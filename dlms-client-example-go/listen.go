@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	dlms "github.com/Gurux/gxdlms-go"
+	"github.com/Gurux/gxdlms-go/types"
+	"github.com/Gurux/gxnet-go"
+)
+
+// GXPushListener receives the push messages that the meters send.
+type GXPushListener struct {
+	reader *GXDLMSReader
+	// keepAlive is the TCP keep-alive period of the meter connections. Zero uses the system default.
+	keepAlive time.Duration
+	// mu serializes parsing because the client is shared between the connections.
+	mu sync.Mutex
+}
+
+// gxPushConnection is the received data and the parse state of one meter. Push can be split
+// to several TCP reads or datagrams, so the state is kept between them.
+type gxPushConnection struct {
+	sender   string
+	rd       *types.GXByteBuffer
+	reply    *dlms.GXReplyData
+	notify   *dlms.GXReplyData
+	lastSeen time.Time
+}
+
+const (
+	// udpSenderIdle is the time after the parse state of the idle UDP sender is removed.
+	udpSenderIdle = 10 * time.Minute
+	// udpSenderMax is the maximum number of the UDP senders which parse state is kept.
+	udpSenderMax = 1024
+)
+
+// gxPushSenders is the parse state of the UDP senders. UDP doesn't tell when the meter goes away,
+// so the idle senders are removed and the number of the senders is limited.
+type gxPushSenders struct {
+	connections map[string]*gxPushConnection
+	idle        time.Duration
+	limit       int
+	evicted     time.Time
+}
+
+// newPushSenders returns the UDP sender state that removes the senders that are idle longer than idle
+// and keeps at most limit senders.
+func newPushSenders(idle time.Duration, limit int) *gxPushSenders {
+	return &gxPushSenders{connections: map[string]*gxPushConnection{}, idle: idle, limit: limit}
+}
+
+// get returns the parse state of the sender. Idle senders are removed once in the idle time
+// and when the limit is reached. The least recently seen sender is removed if all are active.
+func (s *gxPushSenders) get(sender string, now time.Time) *gxPushConnection {
+	c, ok := s.connections[sender]
+	if !ok {
+		if len(s.connections) >= s.limit || now.Sub(s.evicted) >= s.idle {
+			s.evict(now)
+		}
+		if len(s.connections) >= s.limit {
+			var oldest *gxPushConnection
+			for _, it := range s.connections {
+				if oldest == nil || it.lastSeen.Before(oldest.lastSeen) {
+					oldest = it
+				}
+			}
+			delete(s.connections, oldest.sender)
+		}
+		c = newPushConnection(sender)
+		s.connections[sender] = c
+	}
+	c.lastSeen = now
+	return c
+}
+
+// evict removes the senders that haven't sent anything in the idle time.
+func (s *gxPushSenders) evict(now time.Time) {
+	s.evicted = now
+	for sender, it := range s.connections {
+		if now.Sub(it.lastSeen) >= s.idle {
+			delete(s.connections, sender)
+		}
+	}
+}
+
+// newPushConnection returns the parse state of the meter.
+func newPushConnection(sender string) *gxPushConnection {
+	return &gxPushConnection{
+		sender: sender,
+		rd:     types.NewGXByteBuffer(),
+		reply:  dlms.NewGXReplyData(),
+		notify: dlms.NewGXReplyData(),
+	}
+}
+
+// listen waits push messages on the given port until the context is cancelled.
+// UDP is used if -u is given. Otherwise TCP is used.
+func listen(ctx context.Context, settings *gxSettings) error {
+	l := &GXPushListener{reader: newReader(ctx, settings), keepAlive: settings.keepAlive}
+	address := ":" + strconv.Itoa(settings.listen)
+	if m, ok := settings.media.(*gxnet.GXNet); ok && m.Protocol == gxnet.NetworkTypeUDP {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+		defer stop()
+		fmt.Fprintf(os.Stderr, "Listening UDP port %d.\n", settings.listen)
+		return l.serveUDP(conn)
+	}
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	stop := context.AfterFunc(ctx, func() { _ = ln.Close() })
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Listening TCP port %d.\n", settings.listen)
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go l.serveTCP(conn)
+	}
+}
+
+// serveTCP handles push messages of one meter connection.
+func (l *GXPushListener) serveTCP(conn net.Conn) {
+	defer conn.Close()
+	sender := conn.RemoteAddr().String()
+	fmt.Fprintf(os.Stderr, "%s connected.\n", sender)
+	if tcp, ok := conn.(*net.TCPConn); ok && l.keepAlive > 0 {
+		//Push connection is idle between the pushes and the gateway may close it.
+		if err := tcp.SetKeepAliveConfig(net.KeepAliveConfig{Enable: true, Idle: l.keepAlive, Interval: l.keepAlive}); err != nil {
+			l.reader.logger.Warn("keep-alive failed", "sender", sender, "error", err)
+		}
+	}
+	c := newPushConnection(sender)
+	buf := make([]byte, 1518)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			_ = c.rd.Set(buf[:n])
+			l.handleData(c)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s disconnected.\n", sender)
+			return
+		}
+	}
+}
+
+// serveUDP handles push messages that are sent as UDP datagrams.
+func (l *GXPushListener) serveUDP(conn net.PacketConn) error {
+	senders := newPushSenders(udpSenderIdle, udpSenderMax)
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		c := senders.get(addr.String(), time.Now())
+		_ = c.rd.Set(buf[:n])
+		l.handleData(c)
+	}
+}
+
+// handleData parses received data and shows the push messages that are complete.
+// Data of the incomplete push is kept in the connection until the rest is received.
+func (l *GXPushListener) handleData(c *gxPushConnection) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for c.rd.Available() != 0 {
+		pos := c.rd.Position()
+		if _, err := l.reader.client.GetData(c.rd, c.reply, c.notify); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s: invalid push message: %v\n", c.sender, err)
+			c.rd.Clear()
+			c.reply.Clear()
+			c.notify.Clear()
+			return
+		}
+		if c.notify.IsComplete() && !c.notify.IsMoreData() {
+			fmt.Fprintf(os.Stderr, "%s Push from %s:\n", time.Now().Format(time.RFC3339), c.sender)
+			fmt.Fprintln(os.Stderr, l.reader.formatValue(c.notify.Value))
+			c.notify.Clear()
+		} else if c.rd.Position() == pos {
+			//Frame is not complete. Wait more data.
+			return
+		}
+	}
+	c.rd.Clear()
+}
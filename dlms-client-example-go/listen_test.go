@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPushSendersEviction(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	t.Run("idle sender is removed", func(t *testing.T) {
+		s := newPushSenders(time.Minute, 10)
+		s.get("10.0.0.1:4059", start)
+		s.get("10.0.0.2:4059", start.Add(50*time.Second))
+		s.get("10.0.0.3:4059", start.Add(70*time.Second))
+		if _, ok := s.connections["10.0.0.1:4059"]; ok {
+			t.Error("idle sender was not removed")
+		}
+		if len(s.connections) != 2 {
+			t.Errorf("%d senders, expected 2", len(s.connections))
+		}
+	})
+	t.Run("least recently seen sender is removed when the limit is reached", func(t *testing.T) {
+		s := newPushSenders(time.Hour, 2)
+		first := s.get("10.0.0.1:4059", start)
+		s.get("10.0.0.2:4059", start.Add(time.Second))
+		//State of the known sender is kept.
+		if s.get("10.0.0.1:4059", start.Add(2*time.Second)) != first {
+			t.Error("state of the known sender was not kept")
+		}
+		s.get("10.0.0.3:4059", start.Add(3*time.Second))
+		if len(s.connections) != 2 {
+			t.Errorf("%d senders, expected 2", len(s.connections))
+		}
+		if _, ok := s.connections["10.0.0.2:4059"]; ok {
+			t.Error("least recently seen sender was not removed")
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Gurux/gxcommon-go"
+)
+
+// traceMu serializes trace file writes and rotation when several readers share the trace file.
+var traceMu sync.Mutex
+
+// rotateFile renames the file to name.1.ext when it exceeds the maximum size.
+// The previous rotated file is replaced.
+func rotateFile(path string, maxSize int64) error {
+	if maxSize <= 0 {
+		return nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil || fi.Size() < maxSize {
+		return nil
+	}
+	ext := filepath.Ext(path)
+	return os.Rename(path, strings.TrimSuffix(path, ext)+".1"+ext)
+}
+
+// gxTraceFile appends log records to the trace file and rotates it when it grows too big.
+type gxTraceFile struct {
+	path    string
+	maxSize int64
+	// redact removes the password and keys from the record. Trace file is written at debug level
+	// and the security settings are logged.
+	redact func(string) string
+}
+
+// Write appends one log record to the trace file.
+func (f *gxTraceFile) Write(p []byte) (int, error) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	if err := rotateFile(f.path, f.maxSize); err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	if f.redact != nil {
+		if _, err = file.WriteString(f.redact(string(p))); err != nil {
+			return 0, errors.Join(err, file.Close())
+		}
+		return len(p), file.Close()
+	}
+	n, err := file.Write(p)
+	return n, errors.Join(err, file.Close())
+}
+
+// gxLogHandler sends log records to all handlers that accept the level.
+type gxLogHandler []slog.Handler
+
+// Enabled returns true if any of the handlers accepts the level.
+func (h gxLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, it := range h {
+		if it.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle sends the record to the handlers that accept the level.
+func (h gxLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, it := range h {
+		if it.Enabled(ctx, record.Level) {
+			errs = append(errs, it.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs adds the attributes to all handlers.
+func (h gxLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	ret := make(gxLogHandler, len(h))
+	for pos, it := range h {
+		ret[pos] = it.WithAttrs(attrs)
+	}
+	return ret
+}
+
+// WithGroup adds the group to all handlers.
+func (h gxLogHandler) WithGroup(name string) slog.Handler {
+	ret := make(gxLogHandler, len(h))
+	for pos, it := range h {
+		ret[pos] = it.WithGroup(name)
+	}
+	return ret
+}
+
+// traceLevelToSlog converts the trace level to the minimum log level that is shown.
+func traceLevelToSlog(level gxcommon.TraceLevel) slog.Level {
+	switch level {
+	case gxcommon.TraceLevelOff:
+		return slog.LevelError + 4
+	case gxcommon.TraceLevelError:
+		return slog.LevelError
+	case gxcommon.TraceLevelWarning:
+		return slog.LevelWarn
+	case gxcommon.TraceLevelInfo:
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
+
+// LogFormatParse parses the log format. Text and JSON are supported.
+func LogFormatParse(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "text":
+		return "text", nil
+	case "json":
+		return "json", nil
+	}
+	return "", fmt.Errorf("invalid log format %q", value)
+}
+
+// newLogHandler creates text or JSON handler.
+func newLogHandler(format string, w io.Writer, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// newLogger creates the logger that writes to the console with the trace level.
+// All records are written to the trace file if it's given. Redact is applied to the trace file records.
+func newLogger(format string, console io.Writer, level gxcommon.TraceLevel, traceFile string, maxSize int64, redact func(string) string) *slog.Logger {
+	h := gxLogHandler{newLogHandler(format, console, traceLevelToSlog(level))}
+	if traceFile != "" {
+		h = append(h, newLogHandler(format, &gxTraceFile{path: traceFile, maxSize: maxSize, redact: redact}, slog.LevelDebug))
+	}
+	return slog.New(h)
+}
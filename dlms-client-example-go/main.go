@@ -1,34 +1,79 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/Gurux/gxcommon-go"
 	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
 	"github.com/Gurux/gxserial-go"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run runs the command and returns the exit code. Deferred clean-up is done before the application exits.
+func run() int {
 	settings, err := getParameters(os.Args[1:])
 	if err != nil {
 		showHelp()
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		return
+		return 0
 	}
 	if settings == nil {
 		showHelp()
-		return
+		return 0
+	}
+	if settings.version {
+		showVersion()
+		return 0
 	}
 
-	reader := NewGXDLMSReader(settings.client,
-		settings.media,
-		settings.trace,
-		settings.invocationCounterLN,
-		settings.WaitTime)
+	defer settings.close()
 
-	if err := settings.media.Open(); err != nil {
+	ctx, cancel := handleSignals()
+	defer cancel()
+
+	if settings.meterFile != "" {
+		if err := runBatch(ctx, settings.meterFile, settings.parallel, settings.outTemplate); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.listen != 0 {
+		if err := listen(ctx, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.interval > 0 {
+		runInterval(ctx, settings)
+		return 0
+	}
+
+	reader := newReader(ctx, settings)
+
+	if settings.dryRun {
+		if err := reader.DryRun(os.Stdout, settings.readObjects, settings.outputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if err := openMedia(settings.media); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		if settings.media != nil {
 			if _, ok := settings.media.(*gxserial.GXSerial); ok {
@@ -40,7 +85,7 @@ func main() {
 				}
 			}
 		}
-		return
+		return 0
 	}
 	settings.media.SetOnError(func(m gxcommon.IGXMedia, err error) {
 		// log/handle error
@@ -48,35 +93,1138 @@ func main() {
 	})
 
 	settings.media.SetOnTrace(func(m gxcommon.IGXMedia, e gxcommon.TraceEventArgs) {
-		fmt.Printf("Trace: %s\n", e.String())
+		reader.logger.Debug("media", "event", e.String())
 	})
 
-	defer func() { _ = reader.Close() }()
+	defer func() {
+		_ = reader.Close()
+		if reader.Stats != nil {
+			reader.Stats.Print(os.Stderr, 10)
+		}
+		if settings.htmlReport != "" {
+			if err := reader.SaveHTMLReport(settings.htmlReport); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			}
+		}
+	}()
 
-	if len(settings.readObjects) == 0 {
-		if err := reader.ReadAll(settings.outputFile); err != nil {
+	if len(settings.rawFrames) != 0 {
+		if err := sendRaw(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.ping {
+		if err := ping(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if settings.identify {
+		if err := identify(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.detectAddress {
+		if err := detectAddress(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.listObjects {
+		if err := listObjects(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.showAccess {
+		if err := showAccess(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.dumpView != "" {
+		if err := dumpView(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.syncTime {
+		if err := syncTime(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.autoTune {
+		if err := autoTune(reader); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.survey {
+		if err := survey(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.maxDemand {
+		if err := showMaximumDemands(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.demandLN != "" {
+		if err := showDemand(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.entryLN != "" {
+		if err := readEntries(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.rangeLN != "" {
+		if err := readRange(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.csvLN != "" {
+		if err := exportProfile(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.imageInfo {
+		if err := showImageInfo(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.events {
+		if err := readEvents(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.disconnectLN != "" || settings.reconnectLN != "" {
+		if err := remoteControl(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.imageFile != "" {
+		if err := updateImage(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.GenerateSecuritySetupLN != "" {
+		if err := generateCertificates(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.ExportSecuritySetupLN != "" {
+		if err := exportCertificates(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.token != "" {
+		if err := enterToken(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if settings.method != nil {
+		if err := invokeMethod(reader, settings); err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			return
 		}
-		return
+		return 0
+	}
+
+	if len(settings.writeObjects) != 0 {
+		if err := writeValues(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
+	}
+
+	if !settings.hasReadObjects() {
+		if err := reader.InitializeConnection(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 0
+		}
+		if err := readAll(reader, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return 0
 	}
 
 	if err := reader.InitializeConnection(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		return
+		return 0
+	}
+	if err := readObjects(reader, settings); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+	return 0
+}
+
+// newReader creates a reader using the settings.
+func newReader(ctx context.Context, settings *gxSettings) *GXDLMSReader {
+	//Keys may change during the connection, e.g. the dedicated key. Replacer is created for each record.
+	redact := func(s string) string {
+		return secretReplacer(settings.client).Replace(s)
+	}
+	logger := newLogger(settings.logFormat, settings.consoleWriter(), settings.trace, settings.traceFile, settings.traceMaxSize, redact)
+	//Media is not given with --dry-run.
+	if settings.media != nil {
+		logger = logger.With("meter", settings.media.GetName())
+	}
+	reader := NewGXDLMSReader(settings.client,
+		settings.media,
+		settings.trace,
+		settings.invocationCounterLN,
+		settings.WaitTime,
+		logger)
+	reader.Context = ctx
+	reader.RxChunk = settings.RxChunk
+	reader.ProfileWaitTime = settings.ProfileWaitTime
+	reader.RefreshCache = settings.refreshCache
+	reader.StrictTitle = settings.strictTitle
+	reader.BestEffort = settings.bestEffort
+	if settings.icStore != "" {
+		reader.ICStore = NewGXInvocationCounterStore(settings.icStore)
+	}
+	if settings.autoReferencing {
+		//Referencing is known if the association view is read earlier.
+		if ln, ok := referencingFromCache(settings.outputFile); ok {
+			_ = settings.client.SetUseLogicalNameReferencing(ln)
+		} else {
+			reader.AutoReferencing = true
+		}
+	}
+	reader.TempRetryCount = settings.TempRetryCount
+	reader.ReconnectCount = settings.ReconnectCount
+	reader.TempWaitTime = settings.TempWaitTime
+	reader.Jitter = settings.Jitter
+	reader.BackoffBase = settings.BackoffBase
+	reader.BackoffMax = settings.BackoffMax
+	reader.BackoffMultiplier = settings.BackoffMultiplier
+	reader.Limit = settings.Limit
+	reader.ObjectTypes = settings.ObjectTypes
+	reader.UnitMode = settings.UnitMode
+	reader.ApplyScaler = settings.ApplyScaler
+	reader.SkipProfiles = settings.noProfiles
+	reader.SkipReadOut = settings.noReadOut
+	reader.RowOrder = settings.RowOrder
+	reader.RecordFrames = settings.htmlReport != ""
+	reader.HexDumpFile = settings.hexDumpFile
+	reader.Sink = settings.sink
+	if settings.progress {
+		reader.OnProgress = printProgress(settings.consoleWriter())
+	}
+	if settings.stats {
+		reader.Stats = &ReadStats{}
+	}
+	return reader
+}
+
+// handleSignals returns the context that is cancelled on SIGINT or SIGTERM. Reads observe the context
+// and main unwinds normally, so the meter is disconnected and the media is closed by the deferred Close.
+// Second signal terminates the application immediately.
+func handleSignals() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-ch:
+			fmt.Fprintf(os.Stderr, "%s received. Disconnecting.\n", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(ch)
+	}()
+	return ctx, cancel
+}
+
+// ping makes the association and shows the negotiated settings.
+func ping(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	c := settings.client
+	fmt.Printf("Conformance: %s\n", c.NegotiatedConformance().String())
+	negotiated, requested := reader.PDUSize()
+	fmt.Printf("Max PDU size: %d (requested %d)\n", negotiated, requested)
+	fmt.Printf("Authentication: %s\n", c.Authentication().String())
+	fmt.Printf("Security: %s\n", c.Ciphering().Security().String())
+	if c.Ciphering().Security() != enums.SecurityNone {
+		fmt.Printf("Security suite: %s\n", c.Ciphering().SecuritySuite().String())
+	}
+	return reader.Disconnect()
+}
+
+// identify shows the logical device name and the manufacturer of the meter.
+func identify(reader *GXDLMSReader, settings *gxSettings) error {
+	name, err := reader.Identify()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Logical device name: %s\n", name)
+	if id := settings.client.ManufacturerID(); id != "" {
+		fmt.Printf("Manufacturer: %s\n", id)
+		fmt.Printf("Use: -L %s\n", id)
+	}
+	return nil
+}
+
+// detectAddress finds the HDLC client and server address of the meter.
+// Manufacturer ID is read with the found address if -L is not given.
+func detectAddress(reader *GXDLMSReader, settings *gxSettings) error {
+	client, it, err := reader.DetectAddress()
+	if err != nil {
+		return err
+	}
+	server, err := it.serverAddress()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Client address: %d Server address: %d (%s)\n", client, server, it.Scheme)
+	if it.Scheme == "one-byte" {
+		fmt.Printf("Use: -c %d -s %d\n", client, it.Logical)
+	} else {
+		fmt.Printf("Use: -c %d -s %d -l %d\n", client, it.Physical, it.Logical)
+	}
+	if settings.client.ManufacturerID() == "" {
+		//Detection succeeds even if the meter doesn't give the logical device name to the public client.
+		if _, err = reader.Identify(); err != nil {
+			reader.logger.Warn("manufacturer ID not read", "error", err)
+		} else if id := settings.client.ManufacturerID(); id != "" {
+			fmt.Printf("Manufacturer: %s\n", id)
+			fmt.Printf("Use: -L %s\n", id)
+		}
 	}
+	return nil
+}
+
+// listObjects shows the objects of the association view. Attribute values are not read.
+func listObjects(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	for _, it := range *settings.client.Objects() {
+		b := it.Base()
+		fmt.Printf("%s\t%s\tversion %d", b.LogicalName(), b.ObjectType().String(), b.Version)
+		if b.Description != "" {
+			fmt.Printf("\t%s", b.Description)
+		} else if name := describeOBIS(settings.client.Standard(), b.LogicalName()); name != b.LogicalName() {
+			fmt.Printf("\t%s", name)
+		}
+		fmt.Println()
+	}
+	fmt.Fprintf(os.Stderr, "%d objects.\n", len(*settings.client.Objects()))
+	return reader.Disconnect()
+}
+
+// showAccess prints the capability matrix of the association view. Each attribute is shown as R, W, RW or -
+// and each method as X or - depending on what the current association allows.
+func showAccess(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	c := settings.client
+	for _, it := range *c.Objects() {
+		b := it.Base()
+		attributes := make([]string, 0, it.GetAttributeCount())
+		for index := 1; index <= it.GetAttributeCount(); index++ {
+			access := ""
+			if c.CanRead(it, index) {
+				access += "R"
+			}
+			if c.CanWrite(it, index) {
+				access += "W"
+			}
+			if access == "" {
+				access = "-"
+			}
+			attributes = append(attributes, fmt.Sprintf("%d:%s", index, access))
+		}
+		methods := make([]string, 0, it.GetMethodCount())
+		for index := 1; index <= it.GetMethodCount(); index++ {
+			access := "-"
+			if c.CanInvoke(it, index) {
+				access = "X"
+			}
+			methods = append(methods, fmt.Sprintf("%d:%s", index, access))
+		}
+		fmt.Printf("%s\t%s\t%s", b.LogicalName(), b.ObjectType().String(), strings.Join(attributes, " "))
+		if len(methods) != 0 {
+			fmt.Printf("\t%s", strings.Join(methods, " "))
+		}
+		fmt.Println()
+	}
+	return reader.Disconnect()
+}
+
+// dumpView saves the structure of the association view as JSON.
+func dumpView(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	if err := reader.SaveAssociationViewJSON(settings.dumpView); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d objects saved to %s.\n", len(*settings.client.Objects()), settings.dumpView)
+	return reader.Disconnect()
+}
 
+// syncTime synchronizes the meter clock with the host time.
+func syncTime(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	objs := settings.client.Objects().GetObjects(enums.ObjectTypeClock)
+	if len(objs) == 0 {
+		return errors.New("clock object not found")
+	}
+	clock, ok := objs[0].(*objects.GXDLMSClock)
+	if !ok {
+		return errors.New("clock object not found")
+	}
+	return reader.SyncTime(clock, settings.maxDrift)
+}
+
+// readAll reads all objects from the connected meter.
+func readAll(reader *GXDLMSReader, settings *gxSettings) error {
+	outputFile := settings.outputFile
+	if settings.outTemplate != "" {
+		var err error
+		if outputFile, err = reader.ExpandOutputTemplate(settings.outTemplate, time.Now()); err != nil {
+			return err
+		}
+	}
+	err := reader.readAll(outputFile)
+	if failures := reader.Failures(); len(failures) != 0 {
+		fmt.Fprintf(settings.consoleWriter(), "%d attributes failed to read.\n", len(failures))
+	}
+	//File is written also when all attributes are read so an old report is not left behind.
+	if settings.failuresFile != "" {
+		if err := reader.SaveFailuresJSON(settings.failuresFile); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if settings.jsonFile != "" {
+		if err := reader.SaveObjectsJSON(settings.jsonFile); err != nil {
+			return err
+		}
+	}
+	if settings.pushXML != "" {
+		values, err := reader.pushSetupValues()
+		if err != nil {
+			return err
+		}
+		return reader.SavePushXML(settings.pushXML, values)
+	}
+	return nil
+}
+
+// sendRaw sends the --raw frames and shows the replies.
+func sendRaw(reader *GXDLMSReader, settings *gxSettings) error {
+	for _, it := range settings.rawFrames {
+		fmt.Printf("TX: %s\n", types.ToHex(it, true))
+		reply, err := reader.SendRaw(it)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("RX: %s\n", types.ToHex(reply, true))
+	}
+	return nil
+}
+
+// readObjects reads -g objects from the connected meter.
+func readObjects(reader *GXDLMSReader, settings *gxSettings) error {
+	list := make([]types.GXKeyValuePair[objects.IGXDLMSBase, int], 0, len(settings.readObjects))
 	for _, item := range settings.readObjects {
 		obj := settings.client.Objects().FindByLN(enums.ObjectTypeNone, item.Key)
 		if obj == nil {
 			fmt.Fprintf(os.Stderr, "error: object not found: %s\n", item.Key)
 			continue
 		}
-		value, err := reader.Read(obj, item.Value)
+		list = append(list, *types.NewGXKeyValuePair[objects.IGXDLMSBase, int](obj, item.Value))
+	}
+	for _, item := range settings.readShortNames {
+		obj := settings.client.Objects().FindBySN(item.Key)
+		if obj == nil {
+			fmt.Fprintf(os.Stderr, "error: object not found: 0x%04X\n", item.Key)
+			continue
+		}
+		list = append(list, *types.NewGXKeyValuePair[objects.IGXDLMSBase, int](obj, item.Value))
+	}
+	var pushValues []GXPushValue
+	show := func(obj objects.IGXDLMSBase, index int, value any) {
+		ln := obj.Base().LogicalName()
+		if name := describeOBIS(settings.client.Standard(), ln); name != ln {
+			fmt.Fprintf(os.Stderr, "%s:%d (%s) = %s\n", ln, index, name, reader.formatValue(reader.displayValue(obj, index, value)))
+		} else {
+			fmt.Fprintf(os.Stderr, "%s:%d = %s\n", ln, index, reader.formatValue(reader.displayValue(obj, index, value)))
+		}
+		pushValues = append(pushValues, GXPushValue{Target: obj, Index: index, Value: value})
+		if settings.stdoutJSON {
+			if err := json.NewEncoder(os.Stdout).Encode(toJSONRead(obj, index, value)); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			}
+		}
+	}
+	read := false
+	if settings.readList && len(list) != 0 {
+		if err := reader.ReadList(list); err != nil {
+			reader.logger.Warn("read with list failed, objects are read one by one", "error", err)
+		} else {
+			read = true
+			for _, it := range list {
+				var value any
+				if values := it.Key.GetValues(); it.Value <= len(values) {
+					value = values[it.Value-1]
+				}
+				show(it.Key, it.Value, value)
+			}
+		}
+	}
+	if !read {
+		for _, it := range list {
+			value, err := reader.Read(it.Key, it.Value)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: read %s:%d failed: %v\n", it.Key.Base().LogicalName(), it.Value, err)
+				continue
+			}
+			show(it.Key, it.Value, value)
+		}
+	}
+	for _, item := range settings.readSelectors {
+		obj := settings.client.Objects().FindByLN(enums.ObjectTypeNone, item.LN)
+		if obj == nil {
+			fmt.Fprintf(os.Stderr, "error: object not found: %s\n", item.LN)
+			continue
+		}
+		value, err := reader.ReadWithSelector(obj, item.Index, item.Selector, item.Parameters)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: read %s:%d failed: %v\n", item.LN, item.Index, err)
+			continue
+		}
+		show(obj, item.Index, value)
+	}
+	for _, group := range settings.readGroups {
+		if group.File != "" {
+			if err := reader.saveGroupJSON(group); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			}
+		}
+	}
+	if settings.pushXML != "" {
+		return reader.SavePushXML(settings.pushXML, pushValues)
+	}
+	return nil
+}
+
+// runInterval reads the meter on the given interval until the context is cancelled.
+func runInterval(ctx context.Context, settings *gxSettings) {
+	pool := NewGXConnectionPool()
+	defer pool.Close()
+	key := settings.media.GetName()
+	settings.media.SetOnError(func(m gxcommon.IGXMedia, err error) {
+		fmt.Fprintln(os.Stderr, "error:", err)
+	})
+	var metrics *GXMetrics
+	if settings.metricsAddress != "" {
+		metrics = NewGXMetrics()
+		serveMetrics(settings.metricsAddress, metrics)
+		fmt.Fprintf(os.Stderr, "Metrics are served at http://%s/metrics.\n", settings.metricsAddress)
+	}
+	for {
+		start := time.Now()
+		reader, reused, err := pool.Acquire(key, func() (*GXDLMSReader, error) {
+			reader := newReader(ctx, settings)
+			return reader, reader.InitializeConnection()
+		})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error: read %s:%d failed: %v\n", item.Key, item.Value, err)
+			fmt.Fprintf(os.Stderr, "%s connection failed: %v\n", start.Format(time.RFC3339), err)
+			if metrics != nil {
+				metrics.AddAssociationFailure(key)
+			}
+		} else {
+			if metrics != nil {
+				reader.Stats = &ReadStats{}
+			}
+			if reused {
+				fmt.Fprintf(os.Stderr, "%s association reused.\n", start.Format(time.RFC3339))
+			} else {
+				fmt.Fprintf(os.Stderr, "%s connected.\n", start.Format(time.RFC3339))
+			}
+			if !settings.hasReadObjects() {
+				err = readAll(reader, settings)
+			} else {
+				err = readObjects(reader, settings)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			}
+			if metrics != nil {
+				metrics.AddStats(key, reader.Stats)
+			}
+			if settings.pollFile != "" {
+				var pass GXPollPass
+				if err != nil {
+					pass = failedPollPass(start, err)
+				} else {
+					pass = reader.pollPass(start, settings.readTargets())
+				}
+				if err := writePollPass(settings.pollFile, pass); err != nil {
+					fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				}
+			}
+			//Connection is closed after each cycle if it's not reused.
+			if err != nil || !settings.reuse {
+				pool.Evict(key)
+				reader = nil
+			}
+		}
+		next := start.Add(time.Duration(settings.interval) * time.Second)
+		if reader != nil && settings.keepAlive > 0 {
+			//Idle association is kept alive until the next cycle.
+			if err := reader.WaitWithKeepAlive(next, settings.keepAlive); err != nil {
+				pool.Evict(key)
+			}
+		}
+		//Pool is closed when the application is stopped.
+		if err := sleep(ctx, time.Until(next)); err != nil {
+			return
+		}
+	}
+}
+
+// enterToken enters prepayment token to the first token gateway found from the association view.
+func enterToken(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	objs := settings.client.Objects().GetObjects(enums.ObjectTypeTokenGateway)
+	if len(objs) == 0 {
+		return errors.New("token gateway object not found")
+	}
+	gw, ok := objs[0].(*objects.GXDLMSTokenGateway)
+	if !ok {
+		return errors.New("token gateway object not found")
+	}
+	if err := reader.EnterToken(gw, []byte(settings.token), time.Minute); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Token accepted: %s\n", gw.StatusCode.String())
+	return nil
+}
+
+// showDemand shows demand register values and the current period.
+func showDemand(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	obj := settings.client.Objects().FindByLN(enums.ObjectTypeDemandRegister, settings.demandLN)
+	dr, ok := obj.(*objects.GXDLMSDemandRegister)
+	if !ok {
+		return fmt.Errorf("demand register not found: %s", settings.demandLN)
+	}
+	if err := reader.ReadDemandRegister(dr); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Current average value: %s\n", reader.valueWithUnit(dr, 2, dr.CurrentAverageValue))
+	fmt.Fprintf(os.Stderr, "Last average value: %s\n", reader.valueWithUnit(dr, 3, dr.LastAverageValue))
+	fmt.Fprintf(os.Stderr, "Capture time: %s\n", dr.CaptureTime.String())
+	fmt.Fprintf(os.Stderr, "Period: %d s, number of periods: %d\n", dr.Period, dr.NumberOfPeriods)
+	fmt.Fprintf(os.Stderr, "Current period started: %s\n", dr.StartTimeCurrent.String())
+	end := dr.StartTimeCurrent.Value.Add(time.Duration(dr.Period) * time.Second)
+	fmt.Fprintf(os.Stderr, "Current period ends: %s\n", end.Format(time.RFC3339))
+	reset, err := reader.ReadDemandReset()
+	if err != nil {
+		return err
+	}
+	for _, it := range demandResetLines(reset, settings.demandLockout, time.Now()) {
+		fmt.Fprintln(os.Stderr, it)
+	}
+	return nil
+}
+
+// demandResetLines returns the last reset time and the next allowed reset time. Meters don't publish
+// the reset lock-out, so the next allowed reset is known only if the lock-out is given with -demandlockout.
+func demandResetLines(reset GXDemandReset, lockout time.Duration, now time.Time) []string {
+	var ret []string
+	if reset.LastReset.IsZero() {
+		ret = append(ret, "Last reset: unknown. Meter doesn't have the billing period time stamp 0.0.0.1.2.255.")
+	} else {
+		ret = append(ret, "Last reset: "+reset.LastReset.Format(time.RFC3339))
+	}
+	if reset.Schedule != "" {
+		ret = append(ret, "Reset schedule: "+reset.Schedule)
+	} else {
+		ret = append(ret, "Reset schedule: not configured.")
+	}
+	switch {
+	case lockout <= 0:
+		ret = append(ret, "Next allowed reset: unknown. Reset lock-out can't be read from the meter. Give it with -demandlockout.")
+	case reset.LastReset.IsZero():
+		ret = append(ret, "Next allowed reset: unknown. Last reset time is not known.")
+	case now.Before(reset.LastReset.Add(lockout)):
+		ret = append(ret, "Next allowed reset: "+reset.LastReset.Add(lockout).Format(time.RFC3339))
+	default:
+		ret = append(ret, "Next allowed reset: now. Lock-out has ended.")
+	}
+	return ret
+}
+
+// autoTune measures throughput with different HDLC settings and shows the best values.
+func autoTune(reader *GXDLMSReader) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	results, err := reader.AutoTune([]uint16{128, 256, 512, 1024}, []uint8{1, 3, 7})
+	if err != nil {
+		return err
+	}
+	var best *GXTuneResult
+	for i, it := range results {
+		if it.Err != nil {
+			fmt.Fprintf(os.Stderr, "-f %d -w %d: %v\n", it.FrameSize, it.WindowSize, it.Err)
 			continue
 		}
-		fmt.Fprintf(os.Stderr, "%s:%d = %v\n", item.Key, item.Value, value)
+		fmt.Fprintf(os.Stderr, "-f %d -w %d: %d bytes in %s, %.0f bytes/s\n",
+			it.FrameSize, it.WindowSize, it.Bytes, it.Duration.Round(time.Millisecond), it.BytesPerSec)
+		if best == nil || it.BytesPerSec > best.BytesPerSec {
+			best = &results[i]
+		}
+	}
+	if best == nil {
+		return errors.New("all auto tune trials failed")
+	}
+	fmt.Fprintf(os.Stderr, "Recommended settings: -f %d -w %d\n", best.FrameSize, best.WindowSize)
+	return nil
+}
+
+// survey runs the site survey and shows the health summary.
+func survey(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	return reader.Survey(os.Stderr, settings.surveyChecks)
+}
+
+// showImageInfo shows image transfer information and checks that firmware can be updated.
+func showImageInfo(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	objs := settings.client.Objects().GetObjects(enums.ObjectTypeImageTransfer)
+	if len(objs) == 0 {
+		return errors.New("image transfer object not found")
+	}
+	target, ok := objs[0].(*objects.GXDLMSImageTransfer)
+	if !ok {
+		return errors.New("image transfer object not found")
+	}
+	checkErr := reader.CheckImageTransfer(target)
+	for _, idx := range []int{4, 6, 7} {
+		if _, err := reader.Read(target, idx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: read %s:%d failed: %v\n", target.Base().LogicalName(), idx, err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Image transfer enabled: %t\n", target.ImageTransferEnabled)
+	fmt.Fprintf(os.Stderr, "Image block size: %d, max PDU size: %d\n", target.ImageBlockSize, settings.client.MaxReceivePDUSize())
+	fmt.Fprintf(os.Stderr, "First not transferred block: %d\n", target.ImageFirstNotTransferredBlockNumber)
+	fmt.Fprintf(os.Stderr, "Image transfer status: %s\n", target.ImageTransferStatus.String())
+	for _, it := range target.ImageActivateInfo {
+		fmt.Fprintf(os.Stderr, "Image: %s size: %d\n", types.ToHex(it.Identification, true), it.Size)
+	}
+	if checkErr != nil {
+		return checkErr
+	}
+	fmt.Fprintln(os.Stderr, "Firmware update is possible.")
+	return nil
+}
+
+// readEvents shows the entries of the event logs.
+func readEvents(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	return reader.ReadEvents(os.Stdout)
+}
+
+// remoteControl disconnects or reconnects the supply and shows the state after it.
+func remoteControl(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	ln, reconnect := settings.disconnectLN, false
+	if ln == "" {
+		ln, reconnect = settings.reconnectLN, true
+	}
+	obj := settings.client.Objects().FindByLN(enums.ObjectTypeNone, ln)
+	if obj == nil {
+		return fmt.Errorf("object not found: %s", ln)
+	}
+	dc, ok := obj.(*objects.GXDLMSDisconnectControl)
+	if !ok {
+		return fmt.Errorf("%s is %s, not disconnect control", ln, obj.Base().ObjectType().String())
+	}
+	var err error
+	if reconnect {
+		err = reader.RemoteReconnect(dc)
+	} else {
+		err = reader.RemoteDisconnect(dc)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Output state: %t\n", dc.OutputState)
+	fmt.Fprintf(os.Stderr, "Control state: %s\n", dc.ControlState.String())
+	if reconnect && dc.ControlState == enums.ControlStateReadyForReconnection {
+		fmt.Fprintln(os.Stderr, "Meter is ready for reconnection. The supply is reconnected manually.")
+	}
+	return nil
+}
+
+// updateImage transfers the firmware image to the meter and activates it.
+func updateImage(reader *GXDLMSReader, settings *gxSettings) error {
+	image, err := os.ReadFile(settings.imageFile)
+	if err != nil {
+		return err
+	}
+	id := settings.imageID
+	if id == "" {
+		id = strings.TrimSuffix(filepath.Base(settings.imageFile), filepath.Ext(settings.imageFile))
+	}
+	if err = reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err = reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	target, ok := settings.client.Objects().FindByLN(enums.ObjectTypeImageTransfer, settings.imageLN).(*objects.GXDLMSImageTransfer)
+	if !ok {
+		return fmt.Errorf("image transfer object not found: %s", settings.imageLN)
+	}
+	if err = reader.ImageUpdate(target, []byte(id), image); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Image %s (%d bytes) transferred and activated.\n", id, len(image))
+	return nil
+}
+
+// writeDataType returns the data type of the written attribute. If the type is not known,
+// the attribute is read first so the value is not sent to the meter with the wrong type.
+func writeDataType(reader *GXDLMSReader, obj objects.IGXDLMSBase, index int) (enums.DataType, error) {
+	dt, err := obj.GetDataType(index)
+	if err != nil || dt != enums.DataTypeNone {
+		return dt, err
+	}
+	if reader.client.CanRead(obj, index) {
+		if _, err = reader.Read(obj, index); err != nil {
+			return dt, fmt.Errorf("%s:%d: reading the data type failed: %w", obj.Base().LogicalName(), index, err)
+		}
+		if dt, err = obj.GetDataType(index); err != nil || dt != enums.DataTypeNone {
+			return dt, err
+		}
+	}
+	return dt, fmt.Errorf("%s:%d: data type is not known and the attribute can't be read", obj.Base().LogicalName(), index)
+}
+
+// writeValues writes -W2 values to the meter.
+func writeValues(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	list := make([]types.GXKeyValuePair[objects.IGXDLMSBase, int], 0, len(settings.writeObjects))
+	for _, it := range settings.writeObjects {
+		obj := settings.client.Objects().FindByLN(enums.ObjectTypeNone, it.LN)
+		if obj == nil {
+			return fmt.Errorf("object not found: %s", it.LN)
+		}
+		if !settings.client.CanWrite(obj, it.Index) {
+			return fmt.Errorf("%s:%d is not writable with the current association. Access: %s",
+				it.LN, it.Index, obj.Base().GetAccess(it.Index).String())
+		}
+		dt, err := writeDataType(reader, obj, it.Index)
+		if err != nil {
+			return err
+		}
+		value, err := parseValue(it.Value, dt)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", it.LN, it.Index, err)
+		}
+		if _, err = settings.client.UpdateValue(obj, it.Index, value, nil); err != nil {
+			return err
+		}
+		list = append(list, *types.NewGXKeyValuePair[objects.IGXDLMSBase, int](obj, it.Index))
+	}
+	if !settings.batchWrite {
+		for _, it := range list {
+			if err := reader.Write(it.Key, it.Value); err != nil {
+				return fmt.Errorf("write %s:%d failed: %w", it.Key.Base().LogicalName(), it.Value, err)
+			}
+			fmt.Fprintf(os.Stderr, "%s:%d written.\n", it.Key.Base().LogicalName(), it.Value)
+		}
+		return nil
+	}
+	usedList, err := reader.WriteList(list)
+	if usedList {
+		fmt.Fprintln(os.Stderr, "Values are written with one request.")
+	} else {
+		fmt.Fprintln(os.Stderr, "Meter doesn't support set with list. Values are written one by one.")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d values written.\n", len(list))
+	return nil
+}
+
+// showMaximumDemands shows maximum demand values with the capture time.
+func showMaximumDemands(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	objs, err := reader.GetMaximumDemands()
+	if err != nil {
+		return err
+	}
+	if len(objs) == 0 {
+		return errors.New("maximum demand registers not found")
+	}
+	//Maximum demands are always shown with the scaler and unit like the demand register averages.
+	for _, it := range objs {
+		switch o := it.(type) {
+		case *objects.GXDLMSExtendedRegister:
+			fmt.Fprintf(os.Stderr, "%s: %s captured %s\n", o.Base().LogicalName(),
+				reader.FormatWithScaler(o, o.Value), o.CaptureTime.String())
+		case *objects.GXDLMSRegister:
+			fmt.Fprintf(os.Stderr, "%s: %s\n", o.Base().LogicalName(), reader.FormatWithScaler(o, o.Value))
+		}
+	}
+	return nil
+}
+
+// exportCertificates exports client and server certificates from the security setup.
+func exportCertificates(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	obj := settings.client.Objects().FindByLN(enums.ObjectTypeSecuritySetup, settings.ExportSecuritySetupLN)
+	ss, ok := obj.(*objects.GXDLMSSecuritySetup)
+	if !ok {
+		return fmt.Errorf("security setup not found: %s", settings.ExportSecuritySetupLN)
+	}
+	files, err := reader.ExportCertificates(ss)
+	for _, it := range files {
+		fmt.Fprintf(os.Stderr, "Certificate saved: %s\n", it)
+	}
+	return err
+}
+
+// generateCertificates generates new client and server certificates and imports them to the meter.
+func generateCertificates(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	obj := settings.client.Objects().FindByLN(enums.ObjectTypeSecuritySetup, settings.GenerateSecuritySetupLN)
+	ss, ok := obj.(*objects.GXDLMSSecuritySetup)
+	if !ok {
+		return fmt.Errorf("security setup not found: %s", settings.GenerateSecuritySetupLN)
+	}
+	if err := reader.GenerateCertificates(ss); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Certificates generated and imported.")
+	return nil
+}
+
+// exportProfile saves profile generic rows as CSV.
+func exportProfile(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	pg, err := findProfileGeneric(settings, settings.csvLN)
+	if err != nil {
+		return err
+	}
+	return reader.ExportProfileCSV(pg, settings.csvFile)
+}
+
+// findProfileGeneric returns the profile generic from the association view.
+func findProfileGeneric(settings *gxSettings, ln string) (*objects.GXDLMSProfileGeneric, error) {
+	obj := settings.client.Objects().FindByLN(enums.ObjectTypeProfileGeneric, ln)
+	if obj == nil {
+		return nil, fmt.Errorf("object not found: %s", ln)
+	}
+	//Only profile generic objects are searched.
+	return obj.(*objects.GXDLMSProfileGeneric), nil
+}
+
+// readEntries reads profile generic rows by entry and shows them.
+func readEntries(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	pg, err := findProfileGeneric(settings, settings.entryLN)
+	if err != nil {
+		return err
+	}
+	//Capture objects and entries in use are needed to parse and count the rows.
+	for _, idx := range []int{3, 7} {
+		if _, err = reader.Read(pg, idx); err != nil {
+			return err
+		}
+	}
+	rows, err := reader.ReadRowsByEntry(pg, settings.entryIndex, settings.entryCount)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		fmt.Fprintln(os.Stderr, reader.formatValue(row))
+	}
+	fmt.Fprintf(os.Stderr, "%d rows read. Entries in use: %d\n", len(rows), pg.EntriesInUse)
+	return nil
+}
+
+// readRange reads profile generic rows by time range and shows them.
+func readRange(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	pg, err := findProfileGeneric(settings, settings.rangeLN)
+	if err != nil {
+		return err
+	}
+	if _, err = reader.Read(pg, 3); err != nil {
+		return err
+	}
+	start := types.NewGXDateTimeFromTime(settings.rangeStart)
+	end := types.NewGXDateTimeFromTime(settings.rangeEnd)
+	rows, err := reader.ReadRowsByRange(pg, *start, *end)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		fmt.Fprintln(os.Stderr, reader.formatValue(row))
+	}
+	fmt.Fprintf(os.Stderr, "%d rows read.\n", len(rows))
+	return nil
+}
+
+// invokeMethod invokes -x2 method.
+func invokeMethod(reader *GXDLMSReader, settings *gxSettings) error {
+	if err := reader.InitializeConnection(); err != nil {
+		return err
+	}
+	if _, err := reader.GetAssociationView(settings.outputFile); err != nil {
+		return err
+	}
+	it := settings.method
+	obj := settings.client.Objects().FindByLN(enums.ObjectTypeNone, it.LN)
+	if obj == nil {
+		return fmt.Errorf("object not found: %s", it.LN)
+	}
+	value, err := parseMethodValue(obj, it.Index, it.Value)
+	if err != nil {
+		return err
+	}
+	ret, err := reader.MethodValue(obj, it.Index, value)
+	if err != nil {
+		return fmt.Errorf("invoke %s method %d failed: %w", it.LN, it.Index, err)
+	}
+	fmt.Fprintf(os.Stderr, "%s method %d invoked.\n", it.LN, it.Index)
+	if ret != nil {
+		fmt.Fprintf(os.Stderr, "Reply: %s\n", reader.formatValue(ret))
 	}
+	return nil
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDemandResetLines(t *testing.T) {
+	lastReset := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		reset    GXDemandReset
+		lockout  time.Duration
+		expected []string
+	}{
+		{"lock-out is not known", GXDemandReset{LastReset: lastReset}, 0, []string{
+			"Last reset: 2026-10-01T00:00:00Z",
+			"Reset schedule: not configured.",
+			"Next allowed reset: unknown. Reset lock-out can't be read from the meter. Give it with -demandlockout."}},
+		{"reset is locked", GXDemandReset{LastReset: lastReset, Schedule: "00:00:00 01/*/*"}, 24 * time.Hour, []string{
+			"Last reset: 2026-10-01T00:00:00Z",
+			"Reset schedule: 00:00:00 01/*/*",
+			"Next allowed reset: 2026-10-02T00:00:00Z"}},
+		{"lock-out has ended", GXDemandReset{LastReset: lastReset}, time.Hour, []string{
+			"Last reset: 2026-10-01T00:00:00Z",
+			"Reset schedule: not configured.",
+			"Next allowed reset: now. Lock-out has ended."}},
+		{"last reset is not known", GXDemandReset{}, time.Hour, []string{
+			"Last reset: unknown. Meter doesn't have the billing period time stamp 0.0.0.1.2.255.",
+			"Reset schedule: not configured.",
+			"Next allowed reset: unknown. Last reset time is not known."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := demandResetLines(tt.reset, tt.lockout, now); !slices.Equal(got, tt.expected) {
+				t.Errorf("returned %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GXMeterMetrics are the polling counters of one meter.
+type GXMeterMetrics struct {
+	// Reads is the number of successful attribute reads.
+	Reads uint64
+	// ReadFailures is the number of failed attribute reads.
+	ReadFailures uint64
+	// AssociationFailures is the number of failed connections and associations.
+	AssociationFailures uint64
+	// Retries is the number of resent frames and retried temporary failures.
+	Retries uint64
+	// LastRead is the time of the last successful read.
+	LastRead time.Time
+}
+
+// GXMetrics collects the polling metrics of the meters and serves them in Prometheus text format.
+type GXMetrics struct {
+	mu     sync.Mutex
+	meters map[string]*GXMeterMetrics
+}
+
+// NewGXMetrics creates empty metrics.
+func NewGXMetrics() *GXMetrics {
+	return &GXMetrics{meters: make(map[string]*GXMeterMetrics)}
+}
+
+// meter returns the metrics of the meter. Caller must hold the lock.
+func (m *GXMetrics) meter(name string) *GXMeterMetrics {
+	it, ok := m.meters[name]
+	if !ok {
+		it = &GXMeterMetrics{}
+		m.meters[name] = it
+	}
+	return it
+}
+
+// AddStats adds the reads and retries of one poll cycle.
+func (m *GXMetrics) AddStats(name string, stats *ReadStats) {
+	if stats == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	it := m.meter(name)
+	for _, r := range stats.Reads {
+		if r.Failed {
+			it.ReadFailures++
+		} else {
+			it.Reads++
+			it.LastRead = time.Now()
+		}
+	}
+	it.Retries += uint64(stats.Retries)
+}
+
+// AddAssociationFailure adds one failed connection or association.
+func (m *GXMetrics) AddAssociationFailure(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.meter(name).AssociationFailures++
+}
+
+// ServeHTTP writes the metrics in Prometheus text format.
+func (m *GXMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.meters))
+	for name := range m.meters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	write := func(metric, kind, help string, value func(*GXMeterMetrics) float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric, help, metric, kind)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s{meter=%q} %s\n", metric, name, strconv.FormatFloat(value(m.meters[name]), 'f', -1, 64))
+		}
+	}
+	write("dlms_reads_total", "counter", "Successful attribute reads.", func(it *GXMeterMetrics) float64 {
+		return float64(it.Reads)
+	})
+	write("dlms_read_failures_total", "counter", "Failed attribute reads.", func(it *GXMeterMetrics) float64 {
+		return float64(it.ReadFailures)
+	})
+	write("dlms_association_failures_total", "counter", "Failed connections and associations.", func(it *GXMeterMetrics) float64 {
+		return float64(it.AssociationFailures)
+	})
+	write("dlms_retries_total", "counter", "Resent frames and retried temporary failures.", func(it *GXMeterMetrics) float64 {
+		return float64(it.Retries)
+	})
+	write("dlms_last_read_timestamp_seconds", "gauge", "Unix time of the last successful read.", func(it *GXMeterMetrics) float64 {
+		if it.LastRead.IsZero() {
+			return 0
+		}
+		return float64(it.LastRead.Unix())
+	})
+}
+
+// serveMetrics serves the metrics at /metrics until the program is stopped.
+func serveMetrics(address string, metrics *GXMetrics) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	go func() {
+		if err := http.ListenAndServe(address, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "error: metrics: %v\n", err)
+		}
+	}()
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// GXMockExchange is one expected sent frame and the reply that the mock media returns for it.
+type GXMockExchange struct {
+	// TX is the frame that the reader is expected to send. Sent data is not checked if it's nil.
+	TX []byte
+	// RX is the reply. Nothing is replied if it's nil and the receive times out.
+	RX []byte
+}
+
+// GXMockMedia replays a scripted sequence of frames so the reader can be used without a meter.
+// Each sent frame is compared to the next exchange and the reply of the exchange is received.
+type GXMockMedia struct {
+	// Exchanges are the expected frames in the order they are sent.
+	Exchanges []GXMockExchange
+
+	mu            sync.Mutex
+	pos           int
+	buf           []byte
+	open          bool
+	synchronous   bool
+	trace         gxcommon.TraceLevel
+	eop           any
+	err           error
+	bytesSent     uint64
+	bytesReceived uint64
+}
+
+// NewGXMockMedia creates mock media that replays the exchanges.
+func NewGXMockMedia(exchanges ...GXMockExchange) *GXMockMedia {
+	return &GXMockMedia{Exchanges: exchanges}
+}
+
+// Done returns an error if a sent frame was unexpected or all exchanges were not used.
+func (g *GXMockMedia) Done() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err != nil {
+		return g.err
+	}
+	if g.pos != len(g.Exchanges) {
+		return fmt.Errorf("%d of %d exchanges were not sent", len(g.Exchanges)-g.pos, len(g.Exchanges))
+	}
+	return nil
+}
+
+// Send compares the data to the next expected frame and queues the reply.
+func (g *GXMockMedia) Send(data any, receiver string) error {
+	tmp, err := gxcommon.ToBytes(data, binary.BigEndian)
+	if err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.open {
+		return gxcommon.ErrConnectionClosed
+	}
+	g.bytesSent += uint64(len(tmp))
+	if g.pos >= len(g.Exchanges) {
+		g.err = fmt.Errorf("unexpected frame %s", types.ToHex(tmp, true))
+		return g.err
+	}
+	it := g.Exchanges[g.pos]
+	if it.TX != nil && !bytes.Equal(it.TX, tmp) {
+		g.err = fmt.Errorf("exchange %d: sent %s, expected %s", g.pos, types.ToHex(tmp, true), types.ToHex(it.TX, true))
+		return g.err
+	}
+	g.pos++
+	g.buf = append(g.buf, it.RX...)
+	g.bytesReceived += uint64(len(it.RX))
+	return nil
+}
+
+// Receive returns the queued reply. Wait time is not waited because the reply is available immediately.
+func (g *GXMockMedia) Receive(args *gxcommon.ReceiveParameters) (bool, error) {
+	if args.EOP == nil && args.Count == 0 && !args.AllData {
+		return false, errors.New("either Count or EOP must be set")
+	}
+	eop, err := gxcommon.ToBytes(args.EOP, binary.BigEndian)
+	if err != nil {
+		return false, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	index := packetEnd(g.buf, args, eop)
+	if index == -1 {
+		return false, nil
+	}
+	data := bytes.Clone(g.buf[:index])
+	if !args.Peek {
+		g.buf = g.buf[index:]
+	}
+	args.Reply, err = gxcommon.BytesToAny2(data, args.ReplyType, binary.BigEndian)
+	return err == nil, err
+}
+
+// SetOnReceived is not used because replies are received synchronously.
+func (g *GXMockMedia) SetOnReceived(gxcommon.ReceivedEventHandler) {}
+
+// SetOnError is not used because the mock media doesn't fail asynchronously.
+func (g *GXMockMedia) SetOnError(gxcommon.ErrorEventHandler) {}
+
+// SetOnMediaStateChange is not used.
+func (g *GXMockMedia) SetOnMediaStateChange(gxcommon.MediaStateHandler) {}
+
+// SetOnTrace is not used.
+func (g *GXMockMedia) SetOnTrace(gxcommon.TraceEventHandler) {}
+
+// Copy is not supported.
+func (g *GXMockMedia) Copy(target gxcommon.IGXMedia) error {
+	return errors.New("mock media can't be copied")
+}
+
+// GetName returns the name of the media.
+func (g *GXMockMedia) GetName() string {
+	return "mock"
+}
+
+// GetTrace returns the trace level.
+func (g *GXMockMedia) GetTrace() gxcommon.TraceLevel {
+	return g.trace
+}
+
+// SetTrace sets the trace level.
+func (g *GXMockMedia) SetTrace(value gxcommon.TraceLevel) error {
+	g.trace = value
+	return nil
+}
+
+// Open opens the media.
+func (g *GXMockMedia) Open() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.open = true
+	return nil
+}
+
+// IsOpen returns true if the media is open.
+func (g *GXMockMedia) IsOpen() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.open
+}
+
+// Close closes the media.
+func (g *GXMockMedia) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.open = false
+	return nil
+}
+
+// GetMediaType returns the media type.
+func (g *GXMockMedia) GetMediaType() string {
+	return "Mock"
+}
+
+// GetSettings returns empty settings.
+func (g *GXMockMedia) GetSettings() string {
+	return ""
+}
+
+// SetSettings is not supported.
+func (g *GXMockMedia) SetSettings(value string) error {
+	return nil
+}
+
+// GetSynchronous enables synchronous receive mode and returns a function that restores it.
+func (g *GXMockMedia) GetSynchronous() func() {
+	g.mu.Lock()
+	g.synchronous = true
+	g.mu.Unlock()
+	return func() {
+		g.mu.Lock()
+		g.synchronous = false
+		g.mu.Unlock()
+	}
+}
+
+// IsSynchronous returns true if synchronous receive mode is enabled.
+func (g *GXMockMedia) IsSynchronous() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.synchronous
+}
+
+// ResetSynchronousBuffer clears queued replies.
+func (g *GXMockMedia) ResetSynchronousBuffer() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.buf = nil
+}
+
+// GetBytesSent returns the number of sent bytes.
+func (g *GXMockMedia) GetBytesSent() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.bytesSent
+}
+
+// GetBytesReceived returns the number of replied bytes.
+func (g *GXMockMedia) GetBytesReceived() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.bytesReceived
+}
+
+// ResetByteCounters resets sent and received byte counters.
+func (g *GXMockMedia) ResetByteCounters() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.bytesSent = 0
+	g.bytesReceived = 0
+}
+
+// Validate returns always nil.
+func (g *GXMockMedia) Validate() error {
+	return nil
+}
+
+// SetEop sets the end of packet.
+func (g *GXMockMedia) SetEop(value any) {
+	g.eop = value
+}
+
+// GetEop returns the end of packet.
+func (g *GXMockMedia) GetEop() any {
+	return g.eop
+}
+
+// Ensure GXMockMedia implements IGXMedia.
+var _ gxcommon.IGXMedia = (*GXMockMedia)(nil)
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	dlms "github.com/Gurux/gxdlms-go"
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+)
+
+// SNRM request that the client address 16 sends to the server address 1 with the default frame and window sizes.
+var testSNRM = []byte{0x7E, 0xA0, 0x07, 0x03, 0x21, 0x93, 0x0F, 0x01, 0x7E}
+
+// AARQ of the LN association without authentication. Conformance and max PDU size are
+// the values that newTestReader proposes.
+var testAARQ = []byte{0x60, 0x1D,
+	0xA1, 0x09, 0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01, 0x01,
+	0xBE, 0x10, 0x04, 0x0E, 0x01, 0x00, 0x00, 0x00, 0x06, 0x5F, 0x1F, 0x04, 0x00, 0x00, 0x18, 0x1D, 0x04, 0x00}
+
+// Get request of the register 1.0.1.8.0.255 value.
+var testGetRequest = []byte{0xC0, 0x01, 0xC1, 0x00, 0x03, 0x01, 0x00, 0x01, 0x08, 0x00, 0xFF, 0x02, 0x00}
+
+// Get response where the value of the register is 1234 (double-long-unsigned).
+var testGetResponse = []byte{0xC4, 0x01, 0xC1, 0x00, 0x06, 0x00, 0x00, 0x04, 0xD2}
+
+// UA reply of the meter. Frame and window sizes are the defaults.
+var testUA = []byte{0x81, 0x80, 0x12,
+	0x05, 0x01, 0x80,
+	0x06, 0x01, 0x80,
+	0x07, 0x04, 0x00, 0x00, 0x00, 0x01,
+	0x08, 0x04, 0x00, 0x00, 0x00, 0x01}
+
+// AARE of the accepted LN association without ciphering. Get, set, action, selective access and
+// block transfer with get and set are negotiated and the max PDU size is 1024.
+var testAAREAccepted = []byte{0x61, 0x29,
+	0xA1, 0x09, 0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01, 0x01,
+	0xA2, 0x03, 0x02, 0x01, 0x00,
+	0xA3, 0x05, 0xA1, 0x03, 0x02, 0x01, 0x00,
+	0xBE, 0x10, 0x04, 0x0E, 0x08, 0x00, 0x06, 0x5F, 0x1F, 0x04, 0x00, 0x00, 0x18, 0x1D, 0x04, 0x00, 0x00, 0x07}
+
+// AARE where the meter rejects the association permanently because the application context is not supported.
+var testAARERejected = []byte{0x61, 0x17,
+	0xA1, 0x09, 0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01, 0x01,
+	0xA2, 0x03, 0x02, 0x01, 0x01,
+	0xA3, 0x05, 0xA1, 0x03, 0x02, 0x01, 0x02}
+
+// fcs16 returns the HDLC frame check sequence (CRC-16/X.25).
+func fcs16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for range 8 {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0x8408
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return ^crc
+}
+
+// hdlcFrame returns the frame that the meter (server address 1) sends to the client address 16.
+// LLC header is added to the APDU of the I-frame.
+func hdlcFrame(control byte, info []byte, apdu bool) []byte {
+	if apdu {
+		info = append([]byte{0xE6, 0xE7, 0x00}, info...)
+	}
+	return hdlc(0x21, 0x03, control, info)
+}
+
+// hdlcRequest returns the I-frame that the client address 16 sends to the server address 1.
+func hdlcRequest(control byte, apdu []byte) []byte {
+	return hdlc(0x03, 0x21, control, append([]byte{0xE6, 0xE6, 0x00}, apdu...))
+}
+
+// hdlc returns the HDLC frame between the encoded target and source addresses.
+func hdlc(target, source, control byte, info []byte) []byte {
+	size := 7
+	if len(info) != 0 {
+		size += 2 + len(info)
+	}
+	frame := []byte{0xA0 | byte(size>>8), byte(size), target, source, control}
+	if len(info) != 0 {
+		hcs := fcs16(frame)
+		frame = append(frame, byte(hcs), byte(hcs>>8))
+		frame = append(frame, info...)
+	}
+	fcs := fcs16(frame)
+	frame = append(frame, byte(fcs), byte(fcs>>8))
+	return append(append([]byte{0x7E}, frame...), 0x7E)
+}
+
+// newTestReader returns the reader that exchanges the frames with the mock media.
+// Conformance and PDU size are set so the AARQ is always the same.
+func newTestReader(t *testing.T, media *GXMockMedia, interfaceType enums.InterfaceType) *GXDLMSReader {
+	t.Helper()
+	client, err := dlms.NewGXDLMSSecureClient(true, 16, 1, enums.AuthenticationNone, nil, interfaceType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = client.SetProposedConformance(enums.ConformanceGet | enums.ConformanceSet | enums.ConformanceAction |
+		enums.ConformanceSelectiveAccess | enums.ConformanceBlockTransferWithGetOrRead |
+		enums.ConformanceBlockTransferWithSetOrWrite); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.SetMaxReceivePDUSize(1024); err != nil {
+		t.Fatal(err)
+	}
+	r := NewGXDLMSReader(client, media, gxcommon.TraceLevelOff, "", 1000, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.RetryCount = 1
+	if err = media.Open(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestMockMediaAssociationAndRead(t *testing.T) {
+	tests := []struct {
+		name string
+		//Number of the rejected get requests before the meter replies.
+		rejected       int
+		tempRetryCount int
+	}{
+		{"accepted", 0, 0},
+		{"rejected get is retried once by default", 1, 0},
+		{"rejected get is retried until it's accepted", 2, 3},
+	}
+	defer func(old func(context.Context, time.Duration) error) {
+		sleep = old
+	}(sleep)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sleep = func(ctx context.Context, d time.Duration) error {
+				return nil
+			}
+			exchanges := []GXMockExchange{
+				{TX: testSNRM, RX: hdlcFrame(0x73, testUA, false)},
+				{TX: hdlcRequest(0x10, testAARQ), RX: hdlcFrame(0x30, testAAREAccepted, true)},
+			}
+			//Meter rejects the frame (FRMR) and the same get request is sent again.
+			for range tt.rejected {
+				exchanges = append(exchanges, GXMockExchange{TX: hdlcRequest(0x32, testGetRequest), RX: hdlcFrame(0x97, nil, false)})
+			}
+			exchanges = append(exchanges, GXMockExchange{TX: hdlcRequest(0x32, testGetRequest), RX: hdlcFrame(0x52, testGetResponse, true)})
+			media := NewGXMockMedia(exchanges...)
+			r := newTestReader(t, media, enums.InterfaceTypeHDLC)
+			r.TempRetryCount = tt.tempRetryCount
+			r.Stats = &ReadStats{}
+			if err := r.SNRMRequest(); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.AarqRequest(); err != nil {
+				t.Fatalf("association failed: %v", err)
+			}
+			reg, err := objects.NewGXDLMSRegister("1.0.1.8.0.255", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := r.Read(reg, 2)
+			if err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if got := fmt.Sprint(value); got != "1234" {
+				t.Errorf("read %s, expected 1234", got)
+			}
+			if r.Stats.Retries != tt.rejected {
+				t.Errorf("retried %d times, expected %d", r.Stats.Retries, tt.rejected)
+			}
+			if err = media.Done(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMockMediaRejectedAssociation(t *testing.T) {
+	media := NewGXMockMedia(
+		GXMockExchange{TX: testSNRM, RX: hdlcFrame(0x73, testUA, false)},
+		GXMockExchange{TX: hdlcRequest(0x10, testAARQ), RX: hdlcFrame(0x30, testAARERejected, true)})
+	r := newTestReader(t, media, enums.InterfaceTypeHDLC)
+	if err := r.SNRMRequest(); err != nil {
+		t.Fatal(err)
+	}
+	err := r.AarqRequest()
+	var rejected *GXAssociationRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("expected GXAssociationRejectedError, got %v", err)
+	}
+	if err = media.Done(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Gurux/gxdlms-go/enums"
+)
+
+// obisNames are the well-known abstract objects.
+var obisNames = map[string]string{
+	"0.0.1.0.0.255":   "Clock",
+	"0.0.2.0.0.255":   "Modem configuration",
+	"0.0.11.0.0.255":  "Special days table",
+	"0.0.13.0.0.255":  "Activity calendar",
+	"0.0.15.0.0.255":  "End of billing period schedule",
+	"0.0.17.0.0.255":  "Limiter",
+	"0.0.22.0.0.255":  "IEC HDLC setup",
+	"0.0.25.0.0.255":  "TCP-UDP setup",
+	"0.0.25.1.0.255":  "IPv4 setup",
+	"0.0.25.9.0.255":  "Push setup",
+	"0.0.40.0.0.255":  "Current association",
+	"0.0.42.0.0.255":  "Logical device name",
+	"0.0.44.0.0.255":  "Image transfer",
+	"0.0.96.1.0.255":  "Meter serial number",
+	"0.0.96.1.1.255":  "Manufacturer",
+	"0.0.96.1.2.255":  "Firmware version",
+	"0.0.96.2.0.255":  "Number of configuration changes",
+	"0.0.96.3.10.255": "Disconnect control",
+	"0.0.96.14.0.255": "Current active tariff",
+	"0.0.97.98.0.255": "Alarm register",
+	"0.0.98.1.0.255":  "Billing profile",
+	"0.0.99.98.0.255": "Standard event log",
+	"1.0.99.1.0.255":  "Load profile 1",
+	"1.0.99.2.0.255":  "Load profile 2",
+}
+
+// indiaObisNames are the objects of the IS 15959 meters.
+var indiaObisNames = map[string]string{
+	"0.0.0.1.0.255":    "Cumulative billing count",
+	"0.0.94.91.0.255":  "Cumulative tamper count",
+	"0.0.94.91.10.255": "Name plate profile",
+	"1.0.94.91.0.255":  "Instantaneous profile",
+	"1.0.94.91.3.255":  "Instantaneous scaler profile",
+	"1.0.94.91.4.255":  "Block load scaler profile",
+	"1.0.94.91.6.255":  "Billing scaler profile",
+}
+
+// energyNames are the electricity energy quantities (value group C) when D is 8.
+var energyNames = map[int]string{
+	1:  "Active energy import (+A)",
+	2:  "Active energy export (-A)",
+	3:  "Reactive energy import (+R)",
+	4:  "Reactive energy export (-R)",
+	5:  "Reactive energy QI",
+	6:  "Reactive energy QII",
+	7:  "Reactive energy QIII",
+	8:  "Reactive energy QIV",
+	9:  "Apparent energy import",
+	10: "Apparent energy export",
+}
+
+// quantityNames are the electricity power and instantaneous quantities (value group C).
+var quantityNames = map[int]string{
+	1:  "Active power import (+P)",
+	2:  "Active power export (-P)",
+	3:  "Reactive power import (+Q)",
+	4:  "Reactive power export (-Q)",
+	9:  "Apparent power import",
+	10: "Apparent power export",
+	13: "Power factor",
+	14: "Frequency",
+	21: "Active power import L1",
+	31: "Current L1",
+	32: "Voltage L1",
+	33: "Power factor L1",
+	41: "Active power import L2",
+	51: "Current L2",
+	52: "Voltage L2",
+	53: "Power factor L2",
+	61: "Active power import L3",
+	71: "Current L3",
+	72: "Voltage L3",
+	73: "Power factor L3",
+	91: "Neutral current",
+}
+
+// DescribeOBIS returns the name of the well-known OBIS code. Logical name is returned if it's unknown.
+func DescribeOBIS(ln string) string {
+	return describeOBIS(enums.StandardDLMS, ln)
+}
+
+// describeOBIS returns the name of the OBIS code. Objects of the standard are checked first.
+func describeOBIS(standard enums.Standard, ln string) string {
+	if standard == enums.StandardIndia {
+		if it, ok := indiaObisNames[ln]; ok {
+			return it
+		}
+	}
+	if it, ok := obisNames[ln]; ok {
+		return it
+	}
+	parts := strings.Split(ln, ".")
+	if len(parts) != 6 {
+		return ln
+	}
+	var v [6]int
+	for pos, it := range parts {
+		n, err := strconv.Atoi(it)
+		if err != nil {
+			return ln
+		}
+		v[pos] = n
+	}
+	//Electricity objects.
+	if v[0] != 1 {
+		return ln
+	}
+	var name string
+	switch v[3] {
+	case 8:
+		name = energyNames[v[2]]
+	case 7:
+		name = quantityNames[v[2]]
+	case 4:
+		if q, ok := quantityNames[v[2]]; ok {
+			name = "Current average demand " + q
+		}
+	case 5:
+		if q, ok := quantityNames[v[2]]; ok {
+			name = "Last average demand " + q
+		}
+	case 6:
+		if q, ok := quantityNames[v[2]]; ok {
+			name = "Maximum demand " + q
+		}
+	}
+	if name == "" {
+		return ln
+	}
+	if v[4] != 0 {
+		name = fmt.Sprintf("%s rate %d", name, v[4])
+	}
+	return name
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/settings"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// initializePlc discovers the S-FSK PLC meters and registers the meter before SNRM.
+// Meter is selected with the recipient system title (-M). The first meter that answers is used if it's not given.
+// PRIME and G3-PLC are not supported. PRIME meters are registered by the base node and G3-PLC meters
+// by the coordinator, so nothing is done for them and -m is rejected.
+func (r *GXDLMSReader) initializePlc() error {
+	if r.client.InterfaceType() != enums.InterfaceTypePlc &&
+		r.client.InterfaceType() != enums.InterfaceTypePlcHdlc {
+		return nil
+	}
+	plc := r.client.Plc()
+	frame, err := plc.DiscoverRequest()
+	if err != nil {
+		return err
+	}
+	data, err := r.sendPlc(frame)
+	if err != nil {
+		return fmt.Errorf("no PLC node answered the discover request: %w", err)
+	}
+	meters, err := plc.ParseDiscover(types.NewGXByteBufferWithData(data), plc.MacSourceAddress, plc.MacDestinationAddress)
+	if err != nil {
+		return err
+	}
+	var meter *settings.GXDLMSPlcMeterInfo
+	target := r.client.Ciphering().RecipientSystemTitle()
+	for _, it := range meters {
+		r.logger.Info("PLC node discovered", "systemTitle", types.ToHex(it.SystemTitle, false), "address", it.SourceAddress)
+		if meter == nil && (len(target) == 0 || bytes.Equal(target, it.SystemTitle)) {
+			meter = it
+		}
+	}
+	if meter == nil {
+		return errors.New("no PLC node answered the discover request")
+	}
+	frame, err = plc.RegisterRequest(r.client.Ciphering().SystemTitle(), meter.SystemTitle)
+	if err != nil {
+		return err
+	}
+	//Meter doesn't reply to the register request.
+	r.logger.Debug("frame", "direction", "TX", "bytes", len(frame), "data", types.ToHex(frame, true))
+	r.recordFrame(true, frame)
+	r.writeHexDump(true, frame)
+	return r.media.Send(frame, "")
+}
+
+// sendPlc sends the PLC frame and returns all data that is received within the wait time.
+func (r *GXDLMSReader) sendPlc(frame []byte) ([]byte, error) {
+	unlock := r.media.GetSynchronous()
+	defer unlock()
+	p := gxcommon.NewReceiveParameters[[]byte]()
+	p.Count = 1
+	p.AllData = true
+	p.WaitTime = r.WaitTime
+	for attempt := 0; attempt < max(r.RetryCount, 1); attempt++ {
+		r.logger.Debug("frame", "direction", "TX", "bytes", len(frame), "data", types.ToHex(frame, true))
+		r.recordFrame(true, frame)
+		r.writeHexDump(true, frame)
+		if err := r.media.Send(frame, ""); err != nil {
+			return nil, err
+		}
+		succeeded, err := r.media.Receive(p)
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := p.Reply.([]byte); succeeded && ok {
+			r.logger.Debug("frame", "direction", "RX", "bytes", len(data), "data", types.ToHex(data, true))
+			r.recordFrame(false, data)
+			r.writeHexDump(false, data)
+			return data, nil
+		}
+	}
+	return nil, ErrReceiveTimeout
+}
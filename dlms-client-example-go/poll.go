@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+)
+
+// pollTimeFormat is the time format of the {time} placeholder in the poll output file name.
+const pollTimeFormat = "20060102T150405"
+
+// GXPollPass is the read values of one poll cycle.
+type GXPollPass struct {
+	Time    time.Time      `json:"time"`
+	Objects []GXJSONObject `json:"objects"`
+	// Error is set if the read failed. Objects are not saved because they hold the values of the previous cycle.
+	Error string `json:"error,omitempty"`
+}
+
+// failedPollPass returns the poll cycle that failed.
+func failedPollPass(start time.Time, err error) GXPollPass {
+	return GXPollPass{Time: start, Objects: []GXJSONObject{}, Error: err.Error()}
+}
+
+// pollPass returns the values of the -g, -gs and -g2 objects, or all read values if they are not given.
+func (r *GXDLMSReader) pollPass(start time.Time, targets *gxReadGroup) GXPollPass {
+	pass := GXPollPass{Time: start, Objects: []GXJSONObject{}}
+	if !targets.isEmpty() {
+		pass.Objects = r.groupJSON(targets)
+		return pass
+	}
+	for _, it := range *r.client.Objects() {
+		if obj := toJSONObject(it, 0); len(obj.Attributes) != 0 {
+			pass.Objects = append(pass.Objects, obj)
+		}
+	}
+	return pass
+}
+
+// writePollPass writes the poll cycle as one JSON line. If the path contains {time}, it's replaced
+// with the start time of the cycle and each cycle is written to its own file.
+// Otherwise cycles are appended to the file.
+func writePollPass(path string, pass GXPollPass) error {
+	flag := os.O_APPEND | os.O_CREATE | os.O_WRONLY
+	if strings.Contains(path, "{time}") {
+		path = strings.ReplaceAll(path, "{time}", pass.Time.Format(pollTimeFormat))
+		flag = os.O_TRUNC | os.O_CREATE | os.O_WRONLY
+	}
+	data, err := json.Marshal(pass)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"sync"
+)
+
+// GXConnectionPool keeps readers connected between the poll cycles so the media and the
+// association are not re-established every time.
+type GXConnectionPool struct {
+	mu      sync.Mutex
+	readers map[string]*GXDLMSReader
+}
+
+// NewGXConnectionPool creates a new connection pool.
+func NewGXConnectionPool() *GXConnectionPool {
+	return &GXConnectionPool{readers: make(map[string]*GXDLMSReader)}
+}
+
+// Acquire returns a connected reader for the key. If there is no reader in the pool or the
+// association is stale, the old reader is closed and a new one is created with connect.
+// Returned bool tells if the existing association was reused.
+func (p *GXConnectionPool) Acquire(key string, connect func() (*GXDLMSReader, error)) (*GXDLMSReader, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r, ok := p.readers[key]; ok {
+		if r.IsConnected() {
+			return r, true, nil
+		}
+		_ = r.Close()
+		delete(p.readers, key)
+	}
+	r, err := connect()
+	if err != nil {
+		if r != nil {
+			_ = r.Close()
+		}
+		return nil, false, err
+	}
+	p.readers[key] = r
+	return r, false, nil
+}
+
+// Evict closes the reader and removes it from the pool.
+func (p *GXConnectionPool) Evict(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r, ok := p.readers[key]; ok {
+		_ = r.Close()
+		delete(p.readers, key)
+	}
+}
+
+// Close closes all readers in the pool.
+func (p *GXConnectionPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, r := range p.readers {
+		_ = r.Close()
+		delete(p.readers, key)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+)
+
+// GXProfileColumn describes one captured column of the profile generic.
+type GXProfileColumn struct {
+	// Target is the captured object.
+	Target objects.IGXDLMSBase
+	// AttributeIndex is the captured attribute.
+	AttributeIndex int
+	// Scaler of the register. It's 1 if the column is not a register value. The library has
+	// already multiplied the buffer values by it.
+	Scaler float64
+	// Unit is the unit of the register value. It's UnitNone if the column is not a register value.
+	Unit enums.Unit
+}
+
+// Name returns the column name. Unit is added after the logical name if it's known. Ex. 1.0.1.8.0.255:2 [kWh].
+func (c GXProfileColumn) Name() string {
+	name := fmt.Sprintf("%s:%d", c.Target.Base().LogicalName(), c.AttributeIndex)
+	if c.Unit != enums.UnitNone {
+		name += " [" + c.Unit.String() + "]"
+	}
+	return name
+}
+
+// profileColumns pairs the capture objects of the profile generic with the scaler and unit of the captured registers.
+func profileColumns(pg *objects.GXDLMSProfileGeneric) []GXProfileColumn {
+	columns := make([]GXProfileColumn, 0, len(pg.CaptureObjects))
+	for _, it := range pg.CaptureObjects {
+		col := GXProfileColumn{Target: it.Key, AttributeIndex: it.Value.AttributeIndex, Scaler: 1}
+		if unit, ok := objectUnit(it.Key, col.AttributeIndex); ok {
+			col.Unit = unit
+			if scaler := objectScaler(it.Key); scaler != 0 {
+				col.Scaler = scaler
+			}
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+// ReadProfileColumns returns the columns of the profile generic. Capture objects are read if they are not read yet
+// and the scaler and unit are read from the captured registers that don't have the unit.
+func (r *GXDLMSReader) ReadProfileColumns(pg *objects.GXDLMSProfileGeneric) ([]GXProfileColumn, error) {
+	if len(pg.CaptureObjects) == 0 {
+		if _, err := r.Read(pg, 3); err != nil {
+			return nil, err
+		}
+	}
+	for _, it := range pg.CaptureObjects {
+		if unit, ok := objectUnit(it.Key, it.Value.AttributeIndex); !ok || unit != enums.UnitNone {
+			continue
+		}
+		idx := 3
+		if it.Key.Base().ObjectType() == enums.ObjectTypeDemandRegister {
+			idx = 4
+		}
+		if !r.client.CanRead(it.Key, idx) {
+			continue
+		}
+		if _, err := r.Read(it.Key, idx); err != nil {
+			r.logger.Debug("failed reading scaler/unit", "ln", it.Key.Base().LogicalName(), "index", idx, "error", err)
+		}
+	}
+	return profileColumns(pg), nil
+}
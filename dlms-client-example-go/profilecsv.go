@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// ExportProfileCSV saves the captured rows of the profile generic as CSV.
+// All rows are read from the meter if the buffer is empty. The library multiplies the register values
+// by the scaler when the rows are parsed, so the values are saved as they are and the unit is added to the column name.
+func (r *GXDLMSReader) ExportProfileCSV(pg *objects.GXDLMSProfileGeneric, path string) error {
+	columns, err := r.ReadProfileColumns(pg)
+	if err != nil {
+		return err
+	}
+	rows := pg.Buffer
+	if len(rows) == 0 {
+		if rows, err = r.ReadRowsByEntry(pg, 1, 0); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	header := make([]string, 0, len(columns))
+	for _, it := range columns {
+		header = append(header, it.Name())
+	}
+	_ = w.Write(header)
+	for _, row := range rows {
+		line := make([]string, 0, len(row))
+		for _, cell := range row {
+			line = append(line, r.csvValue(cell))
+		}
+		_ = w.Write(line)
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// csvValue converts one profile generic cell to the string.
+func (r *GXDLMSReader) csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return types.ToHex(v, false)
+	case types.GXDateTime:
+		return v.Value.Format(time.RFC3339)
+	case *types.GXDateTime:
+		return v.Value.Format(time.RFC3339)
+	default:
+		return r.formatValue(v)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// The library multiplies the captured register values by the scaler when the buffer is parsed.
+// ExportProfileCSV must not multiply them again.
+func TestExportProfileCSVScalesOnce(t *testing.T) {
+	//One row where the raw value of the register is 1234 (double-long-unsigned).
+	rows := []byte{0xC4, 0x01, 0xC1, 0x00, 0x01, 0x01, 0x02, 0x01, 0x06, 0x00, 0x00, 0x04, 0xD2}
+	media := NewGXMockMedia(
+		GXMockExchange{TX: testSNRM, RX: hdlcFrame(0x73, testUA, false)},
+		GXMockExchange{TX: hdlcRequest(0x10, testAARQ), RX: hdlcFrame(0x30, testAAREAccepted, true)},
+		GXMockExchange{RX: hdlcFrame(0x52, rows, true)})
+	r := newTestReader(t, media, enums.InterfaceTypeHDLC)
+	if err := r.SNRMRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AarqRequest(); err != nil {
+		t.Fatalf("association failed: %v", err)
+	}
+	reg, err := objects.NewGXDLMSRegister("1.0.1.8.0.255", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Scaler and unit are known so they are not read.
+	reg.Scaler = 0.1
+	reg.Unit = enums.UnitActiveEnergy
+	pg, err := objects.NewGXDLMSProfileGeneric("1.0.99.1.0.255", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pg.CaptureObjects = append(pg.CaptureObjects,
+		types.NewGXKeyValuePair[objects.IGXDLMSBase](reg, &objects.GXDLMSCaptureObject{AttributeIndex: 2}))
+	path := filepath.Join(t.TempDir(), "profile.csv")
+	if err = r.ExportProfileCSV(pg, path); err != nil {
+		t.Fatal(err)
+	}
+	if err = media.Done(); err != nil {
+		t.Error(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || len(lines[1]) != 1 {
+		t.Fatalf("expected header and one row with one column, got %q", lines)
+	}
+	value, err := strconv.ParseFloat(lines[1][0], 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(value-123.4) > 1e-9 {
+		t.Errorf("saved %v, expected 123.4 (1234 * 10^-1)", value)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+)
+
+// GXProgress is the progress of ReadAll.
+type GXProgress struct {
+	// Done is the number of read objects.
+	Done int
+	// Total is the number of objects to read.
+	Total int
+	// Elapsed is the time since the reading started.
+	Elapsed time.Duration
+	// Remaining is the estimated time until all objects are read.
+	Remaining time.Duration
+}
+
+// startProgress starts progress reporting. Total is the number of profile generic objects.
+// Objects of the read-out are added when GetReadOut starts.
+func (r *GXDLMSReader) startProgress(total int) {
+	r.progress = GXProgress{Total: total}
+	r.progressStart = time.Now()
+	r.progressActive = true
+}
+
+// stopProgress stops progress reporting.
+func (r *GXDLMSReader) stopProgress() {
+	r.progressActive = false
+}
+
+// addProgressTotal adds the objects of the read-out to the total. Profile generics are counted separately.
+func (r *GXDLMSReader) addProgressTotal(objs []objects.IGXDLMSBase) {
+	if !r.progressActive {
+		return
+	}
+	for _, it := range objs {
+		if it.Base().ObjectType() != enums.ObjectTypeProfileGeneric {
+			r.progress.Total++
+		}
+	}
+}
+
+// stepProgress marks one object read and reports the progress.
+func (r *GXDLMSReader) stepProgress() {
+	if r.OnProgress == nil || !r.progressActive || r.progress.Done >= r.progress.Total {
+		return
+	}
+	r.progress.Done++
+	r.progress.Elapsed = time.Since(r.progressStart)
+	//Remaining time is estimated from the average read time of the objects.
+	r.progress.Remaining = r.progress.Elapsed / time.Duration(r.progress.Done) * time.Duration(r.progress.Total-r.progress.Done)
+	r.OnProgress(r.progress)
+}
+
+// printProgress returns the progress handler that writes the progress to w.
+func printProgress(w io.Writer) func(GXProgress) {
+	return func(p GXProgress) {
+		fmt.Fprintf(w, "%d/%d objects, elapsed %s, remaining %s\n",
+			p.Done, p.Total, p.Elapsed.Round(time.Second), p.Remaining.Round(time.Second))
+	}
+}
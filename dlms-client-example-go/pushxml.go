@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// GXPushValue is one captured value of the data notification.
+type GXPushValue struct {
+	Target objects.IGXDLMSBase
+	Index  int
+	Value  any
+}
+
+// ReadPushValues reads the push object list of the push setup and the values of the captured attributes.
+func (r *GXDLMSReader) ReadPushValues(push *objects.GXDLMSPushSetup) ([]GXPushValue, error) {
+	if _, err := r.Read(push, 2); err != nil {
+		return nil, err
+	}
+	values := make([]GXPushValue, 0, len(push.PushObjectList))
+	for _, it := range push.PushObjectList {
+		index := it.Value.AttributeIndex
+		var value any
+		if index == 1 {
+			//Logical name is not read from the meter.
+			value = types.HexToBytes(lnToHex(it.Key.Base().LogicalName()))
+		} else {
+			v, err := r.Read(it.Key, index)
+			if err != nil {
+				return nil, fmt.Errorf("read %s:%d failed: %w", it.Key.Base().LogicalName(), index, err)
+			}
+			value = v
+		}
+		values = append(values, GXPushValue{Target: it.Key, Index: index, Value: value})
+	}
+	return values, nil
+}
+
+// SavePushXML saves the values as DLMS data notification XML that can be sent to the head-end.
+func (r *GXDLMSReader) SavePushXML(path string, values []GXPushValue) error {
+	var sb strings.Builder
+	sb.WriteString("<DataNotification>\n")
+	sb.WriteString("  <LongInvokeIdAndPriority Value=\"00000001\" />\n")
+	sb.WriteString("  <DateTime Value=\"" + types.ToHex(r.encodeDateTime(time.Now()), false) + "\" />\n")
+	sb.WriteString("  <NotificationBody>\n")
+	sb.WriteString("    <DataValue>\n")
+	fmt.Fprintf(&sb, "      <Structure Qty=\"%02X\" >\n", len(values))
+	for _, it := range values {
+		fmt.Fprintf(&sb, "        <!--%s-->\n", xmlComment(fmt.Sprintf("%s:%d", it.Target.Base().LogicalName(), it.Index)))
+		r.writePushXMLValue(&sb, it.Value, "        ")
+	}
+	sb.WriteString("      </Structure>\n")
+	sb.WriteString("    </DataValue>\n")
+	sb.WriteString("  </NotificationBody>\n")
+	sb.WriteString("</DataNotification>\n")
+	return os.WriteFile(path, []byte(sb.String()), 0o644)
+}
+
+// writePushXMLValue writes one value using the same data types that the meter uses in the push.
+func (r *GXDLMSReader) writePushXMLValue(sb *strings.Builder, value any, indent string) {
+	tag := func(name string, v string) {
+		fmt.Fprintf(sb, "%s<%s Value=\"%s\" />\n", indent, name, escapeXML(v))
+	}
+	list := func(name string, items []any) {
+		fmt.Fprintf(sb, "%s<%s Qty=\"%02X\" >\n", indent, name, len(items))
+		for _, it := range items {
+			r.writePushXMLValue(sb, it, indent+"  ")
+		}
+		fmt.Fprintf(sb, "%s</%s>\n", indent, name)
+	}
+	switch v := value.(type) {
+	case nil:
+		fmt.Fprintf(sb, "%s<None />\n", indent)
+	case bool:
+		if v {
+			tag("Boolean", "true")
+		} else {
+			tag("Boolean", "false")
+		}
+	case int8:
+		tag("Int8", fmt.Sprintf("%02X", uint8(v)))
+	case int16:
+		tag("Int16", fmt.Sprintf("%04X", uint16(v)))
+	case int32:
+		tag("Int32", fmt.Sprintf("%08X", uint32(v)))
+	case int64:
+		tag("Int64", fmt.Sprintf("%016X", uint64(v)))
+	case int:
+		tag("Int32", fmt.Sprintf("%08X", uint32(v)))
+	case uint8:
+		tag("UInt8", fmt.Sprintf("%02X", v))
+	case uint16:
+		tag("UInt16", fmt.Sprintf("%04X", v))
+	case uint32:
+		tag("UInt32", fmt.Sprintf("%08X", v))
+	case uint64:
+		tag("UInt64", fmt.Sprintf("%016X", v))
+	case float32:
+		tag("Float32", fmt.Sprint(v))
+	case float64:
+		tag("Float64", fmt.Sprint(v))
+	case string:
+		tag("String", v)
+	case []byte:
+		tag("OctetString", types.ToHex(v, false))
+	case types.GXDateTime:
+		//Date and time is sent as an octet string in the push.
+		tag("OctetString", types.ToHex(r.encodeDateTime(v.Value), false))
+	case *types.GXDateTime:
+		tag("OctetString", types.ToHex(r.encodeDateTime(v.Value), false))
+	case types.GXArray:
+		list("Array", v)
+	case types.GXStructure:
+		list("Structure", v)
+	case []any:
+		list("Structure", v)
+	default:
+		tag("String", fmt.Sprint(v))
+	}
+}
+
+// escapeXML escapes the attribute value. Strings are written as they are read from the meter.
+func escapeXML(value string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(value))
+	return sb.String()
+}
+
+// xmlComment removes the double hyphens and the trailing hyphen that are not allowed in the XML comment.
+func xmlComment(value string) string {
+	for strings.Contains(value, "--") {
+		value = strings.ReplaceAll(value, "--", "-")
+	}
+	return strings.TrimSuffix(value, "-")
+}
+
+// encodeDateTime encodes the time as 12 byte COSEM date-time.
+func (r *GXDLMSReader) encodeDateTime(t time.Time) []byte {
+	_, offset := t.Zone()
+	deviation := int16(-offset / 60)
+	if r.client.UseUtc2NormalTime() {
+		deviation = -deviation
+	}
+	dow := byte(t.Weekday())
+	if dow == 0 {
+		dow = 7
+	}
+	buff := make([]byte, 12)
+	binary.BigEndian.PutUint16(buff[0:], uint16(t.Year()))
+	buff[2] = byte(t.Month())
+	buff[3] = byte(t.Day())
+	buff[4] = dow
+	buff[5] = byte(t.Hour())
+	buff[6] = byte(t.Minute())
+	buff[7] = byte(t.Second())
+	buff[8] = byte(t.Nanosecond() / 10000000)
+	binary.BigEndian.PutUint16(buff[9:], uint16(deviation))
+	buff[11] = 0
+	return buff
+}
+
+// lnToHex converts the logical name to the hex string.
+func lnToHex(ln string) string {
+	var sb strings.Builder
+	for _, it := range strings.Split(ln, ".") {
+		v, _ := strconv.Atoi(it)
+		fmt.Fprintf(&sb, "%02X", v)
+	}
+	return sb.String()
+}
+
+// pushSetupValues reads the values of the first push setup in the association view.
+func (r *GXDLMSReader) pushSetupValues() ([]GXPushValue, error) {
+	for _, it := range r.client.Objects().GetObjects(enums.ObjectTypePushSetup) {
+		if push, ok := it.(*objects.GXDLMSPushSetup); ok {
+			return r.ReadPushValues(push)
+		}
+	}
+	return nil, fmt.Errorf("push setup object not found. Use -g to select the pushed objects")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// RowOrder defines the order of the profile generic rows.
+type RowOrder int
+
+const (
+	// RowOrderMeter returns the rows in the order that the meter sends them.
+	RowOrderMeter RowOrder = iota
+	// RowOrderAscending returns the oldest row first.
+	RowOrderAscending
+	// RowOrderDescending returns the newest row first.
+	RowOrderDescending
+)
+
+// RowOrderParse parses the row order.
+func RowOrderParse(value string) (RowOrder, error) {
+	switch strings.ToLower(value) {
+	case "meter":
+		return RowOrderMeter, nil
+	case "asc":
+		return RowOrderAscending, nil
+	case "desc":
+		return RowOrderDescending, nil
+	default:
+		return RowOrderMeter, fmt.Errorf("invalid row order %q (meter, asc, desc)", value)
+	}
+}
+
+// rowTime returns the time of the date-time value.
+func rowTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case types.GXDateTime:
+		return v.Value, true
+	case *types.GXDateTime:
+		if v != nil {
+			return v.Value, true
+		}
+	case time.Time:
+		return v, true
+	}
+	return time.Time{}, false
+}
+
+// orderRows returns the rows in chronological order that RowOrder defines.
+// Rows are sorted by the clock column if it's captured. Otherwise the sort method of the
+// profile generic tells if the meter sends the newest row first.
+func (r *GXDLMSReader) orderRows(pg *objects.GXDLMSProfileGeneric, rows [][]any) [][]any {
+	if r.RowOrder == RowOrderMeter || len(rows) < 2 {
+		return rows
+	}
+	rows = slices.Clone(rows)
+	column := slices.IndexFunc(pg.CaptureObjects, func(it *types.GXKeyValuePair[objects.IGXDLMSBase, *objects.GXDLMSCaptureObject]) bool {
+		return it.Key.Base().ObjectType() == enums.ObjectTypeClock
+	})
+	if column != -1 {
+		slices.SortStableFunc(rows, func(a, b []any) int {
+			if column >= len(a) || column >= len(b) {
+				return 0
+			}
+			x, ok1 := rowTime(a[column])
+			y, ok2 := rowTime(b[column])
+			if !ok1 || !ok2 {
+				return 0
+			}
+			return x.Compare(y)
+		})
+	} else {
+		if pg.SortMethod == 0 && r.client.CanRead(pg, 5) {
+			_, _ = r.Read(pg, 5)
+		}
+		switch pg.SortMethod {
+		case enums.SortMethodFiFo, 0:
+		case enums.SortMethodLiFo:
+			slices.Reverse(rows)
+		default:
+			//Rows are sorted by the value of the sort object and the time is unknown.
+			r.logger.Debug("rows are not in chronological order", "ln", pg.Base().LogicalName(), "sort", pg.SortMethod.String())
+			return rows
+		}
+	}
+	if r.RowOrder == RowOrderDescending {
+		slices.Reverse(rows)
+	}
+	return rows
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Gurux/gxnet-go"
+)
+
+// Host names are not resolved when the flags are parsed.
+func TestHostAddressFamily(t *testing.T) {
+	tests := []struct {
+		value    string
+		hostName string
+		ipv6     bool
+	}{
+		{"192.168.1.10", "192.168.1.10", false},
+		{"fe80::1", "[fe80::1]", true},
+		{"[fe80::1%eth0]:4059", "[fe80::1%eth0]", true},
+		{"meter.invalid:4059", "meter.invalid", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			s, err := getParameters([]string{"-h", tt.value})
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, ok := s.media.(*gxnet.GXNet)
+			if !ok {
+				t.Fatalf("media is %T, expected net media", s.media)
+			}
+			if m.HostName != tt.hostName || m.UseIPv6 != tt.ipv6 {
+				t.Errorf("host %q IPv6 %v, expected %q IPv6 %v", m.HostName, m.UseIPv6, tt.hostName, tt.ipv6)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// GXSigner signs the digest with the client signing key.
+// Ciphering calls it when the APDU or the HLS challenge is signed, so the private key doesn't need to be in memory.
+type GXSigner interface {
+	// Public returns the public key of the signing key.
+	Public() *ecdsa.PublicKey
+	// Sign returns the ASN.1 encoded ECDSA signature of the digest.
+	Sign(digest []byte) ([]byte, error)
+	// Close releases the signing key, e.g. closes the PKCS #11 session.
+	Close() error
+}
+
+// GXFileSigner signs with the private key that is read from the PEM file.
+type GXFileSigner struct {
+	Key *ecdsa.PrivateKey
+}
+
+// Public returns the public key of the signing key.
+func (s *GXFileSigner) Public() *ecdsa.PublicKey {
+	return &s.Key.PublicKey
+}
+
+// Sign returns the ASN.1 encoded ECDSA signature of the digest.
+func (s *GXFileSigner) Sign(digest []byte) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, s.Key, digest)
+}
+
+// Close does nothing because the key is only in memory.
+func (s *GXFileSigner) Close() error {
+	return nil
+}
+
+// gxPkcs11URI is the PKCS #11 URI (RFC 7512) of the signing key.
+type gxPkcs11URI struct {
+	//Token label.
+	Token string
+	//Key label.
+	Object string
+	//Key ID.
+	ID []byte
+	//PKCS #11 module that is loaded.
+	ModulePath string
+	//User PIN.
+	Pin string
+}
+
+// parsePkcs11URI parses the PKCS #11 URI. PIN is read from PKCS11_PIN environment variable
+// if it's not in the URI so it's not visible in the process list.
+func parsePkcs11URI(value string) (*gxPkcs11URI, error) {
+	path, ok := strings.CutPrefix(value, "pkcs11:")
+	if !ok {
+		return nil, fmt.Errorf("invalid -hsm %q. URI must start with pkcs11:", value)
+	}
+	path, query, _ := strings.Cut(path, "?")
+	ret := &gxPkcs11URI{}
+	for _, it := range strings.Split(path, ";") {
+		if it == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(it, "=")
+		v, err := url.PathUnescape(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -hsm %q: %w", value, err)
+		}
+		switch k {
+		case "token":
+			ret.Token = v
+		case "object":
+			ret.Object = v
+		case "id":
+			ret.ID = []byte(v)
+		}
+	}
+	for _, it := range strings.Split(query, "&") {
+		if it == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(it, "=")
+		v, err := url.QueryUnescape(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -hsm %q: %w", value, err)
+		}
+		switch k {
+		case "module-path":
+			ret.ModulePath = v
+		case "pin-value":
+			ret.Pin = v
+		}
+	}
+	if ret.Pin == "" {
+		ret.Pin = os.Getenv("PKCS11_PIN")
+	}
+	if ret.ModulePath == "" {
+		return nil, fmt.Errorf("invalid -hsm %q. module-path is missing", value)
+	}
+	if ret.Object == "" && ret.ID == nil {
+		return nil, fmt.Errorf("invalid -hsm %q. object or id is missing", value)
+	}
+	return ret, nil
+}
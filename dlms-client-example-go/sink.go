@@ -0,0 +1,25 @@
+package main
+
+import (
+	"time"
+
+	"github.com/Gurux/gxdlms-go/objects"
+)
+
+// GXValueSink receives the attribute values that are read from the meter.
+type GXValueSink interface {
+	// Publish is called after the attribute value is read.
+	Publish(obj objects.IGXDLMSBase, index int, value any, timestamp time.Time) error
+	// Close closes the sink.
+	Close() error
+}
+
+// publish sends the read value to the sink. Failures are logged so they don't stop the reading.
+func (r *GXDLMSReader) publish(obj objects.IGXDLMSBase, index int, value any) {
+	if r.Sink == nil {
+		return
+	}
+	if err := r.Sink.Publish(obj, index, value, time.Now()); err != nil {
+		r.logger.Warn("publishing failed", "ln", obj.Base().LogicalName(), "index", index, "error", err)
+	}
+}
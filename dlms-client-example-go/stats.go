@@ -0,0 +1,93 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// GXReadTime is the round-trip time of one attribute read.
+type GXReadTime struct {
+	LogicalName string
+	Index       int
+	Duration    time.Duration
+	Failed      bool
+}
+
+// ReadStats contains the timing statistics of the reads. Methods can be called for nil stats.
+type ReadStats struct {
+	// Reads are the attribute reads in the read order.
+	Reads []GXReadTime
+	// FramesSent is the number of sent frames.
+	FramesSent int
+	// FramesReceived is the number of received frames.
+	FramesReceived int
+	// BytesSent is the number of sent bytes.
+	BytesSent int
+	// BytesReceived is the number of received bytes.
+	BytesReceived int
+	// Retries is the number of resent frames and retried temporary failures.
+	Retries int
+}
+
+// addRead adds the round-trip time of the read.
+func (s *ReadStats) addRead(ln string, index int, d time.Duration, err error) {
+	if s != nil {
+		s.Reads = append(s.Reads, GXReadTime{LogicalName: ln, Index: index, Duration: d, Failed: err != nil})
+	}
+}
+
+// addFrame adds the sent or received frame.
+func (s *ReadStats) addFrame(sent bool, size int) {
+	if s == nil {
+		return
+	}
+	if sent {
+		s.FramesSent++
+		s.BytesSent += size
+	} else {
+		s.FramesReceived++
+		s.BytesReceived += size
+	}
+}
+
+// addRetry adds one retry.
+func (s *ReadStats) addRetry() {
+	if s != nil {
+		s.Retries++
+	}
+}
+
+// Print writes the summary with the slowest reads.
+func (s *ReadStats) Print(w io.Writer, slowest int) {
+	var total time.Duration
+	failed := 0
+	for _, it := range s.Reads {
+		total += it.Duration
+		if it.Failed {
+			failed++
+		}
+	}
+	fmt.Fprintf(w, "Reads: %d (%d failed), total %s\n", len(s.Reads), failed, total.Round(time.Millisecond))
+	fmt.Fprintf(w, "Sent: %d bytes in %d frames\n", s.BytesSent, s.FramesSent)
+	fmt.Fprintf(w, "Received: %d bytes in %d frames\n", s.BytesReceived, s.FramesReceived)
+	if s.FramesReceived != 0 {
+		fmt.Fprintf(w, "Average received PDU size: %d bytes\n", s.BytesReceived/s.FramesReceived)
+	}
+	fmt.Fprintf(w, "Retries: %d\n", s.Retries)
+	reads := slices.Clone(s.Reads)
+	slices.SortStableFunc(reads, func(a, b GXReadTime) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	if len(reads) > slowest {
+		reads = reads[:slowest]
+	}
+	if len(reads) != 0 {
+		fmt.Fprintln(w, "Slowest reads:")
+	}
+	for _, it := range reads {
+		fmt.Fprintf(w, "  %s:%d %s\n", it.LogicalName, it.Index, it.Duration.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+)
+
+// surveyCheck is one check of the site survey.
+type surveyCheck struct {
+	name        string
+	description string
+	run         func(r *GXDLMSReader, w io.Writer) error
+}
+
+// surveyChecks are the available site survey checks in the order they are run.
+var surveyChecks = []surveyCheck{
+	{"identity", "Meter identity", (*GXDLMSReader).describeIdentity},
+	{"clock", "Clock and time zone", (*GXDLMSReader).describeClock},
+	{"status", "Status and error registers", (*GXDLMSReader).describeStatus},
+	{"relay", "Relay state", (*GXDLMSReader).describeRelay},
+	{"signal", "Signal diagnostics", (*GXDLMSReader).describeSignal},
+	{"billing", "Last billing", (*GXDLMSReader).describeBilling},
+	{"profile", "Load profile sample", (*GXDLMSReader).describeLoadProfile},
+}
+
+// surveyCheckNames returns the names of the available survey checks.
+func surveyCheckNames() []string {
+	names := make([]string, 0, len(surveyChecks))
+	for _, it := range surveyChecks {
+		names = append(names, it.name)
+	}
+	return names
+}
+
+// parseSurveyChecks validates the comma separated list of survey checks.
+func parseSurveyChecks(value string) ([]string, error) {
+	var ret []string
+	for _, it := range strings.Split(value, ",") {
+		it = strings.ToLower(strings.TrimSpace(it))
+		if it == "" {
+			continue
+		}
+		if !slices.Contains(surveyCheckNames(), it) {
+			return nil, fmt.Errorf("unknown survey check %q (%s)", it, strings.Join(surveyCheckNames(), ", "))
+		}
+		ret = append(ret, it)
+	}
+	return ret, nil
+}
+
+// Survey runs the selected checks and writes a health summary. All checks are run if names is empty.
+// Association view must be read before the survey is run.
+func (r *GXDLMSReader) Survey(w io.Writer, names []string) error {
+	failed := 0
+	for _, it := range surveyChecks {
+		if len(names) != 0 && !slices.Contains(names, it.name) {
+			continue
+		}
+		fmt.Fprintf(w, "== %s ==\n", it.description)
+		if err := it.run(r, w); err != nil {
+			failed++
+			fmt.Fprintf(w, "[FAIL] %s: %v\n", it.name, err)
+		} else {
+			fmt.Fprintf(w, "[OK] %s\n", it.name)
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d survey check(s) failed", failed)
+	}
+	return nil
+}
+
+// describeObject reads the given attributes and writes them with the object description.
+func (r *GXDLMSReader) describeObject(w io.Writer, obj objects.IGXDLMSBase, indexes ...int) error {
+	var ret error
+	for _, idx := range indexes {
+		if !r.client.CanRead(obj, idx) {
+			continue
+		}
+		val, err := r.Read(obj, idx)
+		if err != nil {
+			ret = errors.Join(ret, fmt.Errorf("%s:%d: %w", obj.Base().LogicalName(), idx, err))
+			continue
+		}
+		fmt.Fprintf(w, "%s %s:%d = %s\n", obj.Base().ObjectType().String(), obj.Base().LogicalName(), idx,
+			r.formatValue(r.displayValue(obj, idx, val)))
+	}
+	return ret
+}
+
+// describeByLN describes the objects with the given logical names. Missing objects are skipped.
+func (r *GXDLMSReader) describeByLN(w io.Writer, lns ...string) error {
+	var ret error
+	found := false
+	for _, ln := range lns {
+		obj := r.client.Objects().FindByLN(enums.ObjectTypeNone, ln)
+		if obj == nil {
+			continue
+		}
+		found = true
+		ret = errors.Join(ret, r.describeObject(w, obj, 2))
+	}
+	if !found {
+		return errors.New("objects not found")
+	}
+	return ret
+}
+
+// describeByType describes the given attributes of all objects of the given type.
+func (r *GXDLMSReader) describeByType(w io.Writer, ot enums.ObjectType, indexes ...int) error {
+	objs := r.client.Objects().GetObjects(ot)
+	if len(objs) == 0 {
+		return fmt.Errorf("%s object not found", ot.String())
+	}
+	var ret error
+	for _, it := range objs {
+		ret = errors.Join(ret, r.describeObject(w, it, indexes...))
+	}
+	return ret
+}
+
+// describeIdentity describes logical device name, serial number and firmware version.
+func (r *GXDLMSReader) describeIdentity(w io.Writer) error {
+	return r.describeByLN(w, "0.0.42.0.0.255", "0.0.96.1.0.255", "1.0.0.2.0.255")
+}
+
+// describeClock describes time, time zone and daylight saving settings.
+func (r *GXDLMSReader) describeClock(w io.Writer) error {
+	return r.describeByType(w, enums.ObjectTypeClock, 2, 3, 4, 8)
+}
+
+// describeStatus describes error and alarm registers.
+func (r *GXDLMSReader) describeStatus(w io.Writer) error {
+	return r.describeByLN(w, "0.0.97.97.0.255", "0.0.97.98.0.255", "0.0.96.5.0.255")
+}
+
+// describeRelay describes output and control state of the disconnect control.
+func (r *GXDLMSReader) describeRelay(w io.Writer) error {
+	return r.describeByType(w, enums.ObjectTypeDisconnectControl, 2, 3, 4)
+}
+
+// describeSignal describes modem operator, status and cell info.
+func (r *GXDLMSReader) describeSignal(w io.Writer) error {
+	return r.describeByType(w, enums.ObjectTypeGSMDiagnostic, 2, 3, 4, 5, 6)
+}
+
+// describeBilling describes the last entry of the billing profile.
+func (r *GXDLMSReader) describeBilling(w io.Writer) error {
+	return r.describeLastRows(w, "0.0.98.1.0.255", 1)
+}
+
+// describeLoadProfile describes the last entries of the load profile.
+func (r *GXDLMSReader) describeLoadProfile(w io.Writer) error {
+	return r.describeLastRows(w, "1.0.99.1.0.255", 3)
+}
+
+// describeLastRows describes the last rows of the profile generic.
+func (r *GXDLMSReader) describeLastRows(w io.Writer, ln string, count uint32) error {
+	pg, ok := r.client.Objects().FindByLN(enums.ObjectTypeProfileGeneric, ln).(*objects.GXDLMSProfileGeneric)
+	if !ok {
+		return fmt.Errorf("profile generic %s not found", ln)
+	}
+	if len(pg.CaptureObjects) == 0 {
+		if _, err := r.Read(pg, 3); err != nil {
+			return err
+		}
+	}
+	if _, err := r.Read(pg, 7); err != nil {
+		return err
+	}
+	if pg.EntriesInUse == 0 {
+		fmt.Fprintf(w, "%s: no entries\n", ln)
+		return nil
+	}
+	if count > pg.EntriesInUse {
+		count = pg.EntriesInUse
+	}
+	rows, err := r.ReadRowsByEntry(pg, pg.EntriesInUse-count+1, count)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s: %s\n", ln, r.formatValue(row))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// ExpandOutputTemplate replaces the placeholders of the output file name template with the values of the
+// connected meter and creates the parent directories. Placeholders are {systemTitle}, {ldn}, {meter},
+// {date} and {time}. Logical device name is read from the meter only if it's used.
+func (r *GXDLMSReader) ExpandOutputTemplate(template string, now time.Time) (string, error) {
+	systemTitle := r.client.SourceSystemTitle()
+	if len(systemTitle) == 0 {
+		systemTitle = r.client.Ciphering().RecipientSystemTitle()
+	}
+	values := []string{
+		"{systemTitle}", types.ToHex(systemTitle, false),
+		"{meter}", r.media.GetName(),
+		"{date}", now.Format("20060102"),
+		"{time}", now.Format(pollTimeFormat),
+	}
+	if strings.Contains(template, "{ldn}") {
+		values = append(values, "{ldn}", string(r.logicalDeviceName()))
+	}
+	for pos := 1; pos < len(values); pos += 2 {
+		values[pos] = safeFileName(values[pos])
+	}
+	path := strings.NewReplacer(values...).Replace(template)
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+	}
+	r.logger.Debug("output file", "path", path)
+	return path, nil
+}
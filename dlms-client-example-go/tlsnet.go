@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxnet-go"
+)
+
+// GXTLSNet is TCP media that is secured with TLS.
+// Host name, port, timeout and trace settings are taken from the embedded net media.
+type GXTLSNet struct {
+	*gxnet.GXNet
+	// Config is the TLS configuration that is used when the connection is opened.
+	Config *tls.Config
+
+	mu            sync.Mutex
+	conn          *tls.Conn
+	buf           []byte
+	wait          chan struct{}
+	synchronous   bool
+	onReceive     gxcommon.ReceivedEventHandler
+	onError       gxcommon.ErrorEventHandler
+	bytesSent     uint64
+	bytesReceived uint64
+}
+
+// NewGXTLSNet creates TLS media that connects to the host and port of the net media.
+func NewGXTLSNet(media *gxnet.GXNet, config *tls.Config) *GXTLSNet {
+	return &GXTLSNet{GXNet: media, Config: config, wait: make(chan struct{})}
+}
+
+// newTLSConfig creates the TLS configuration from the CA, client certificate and client key files.
+func newTLSConfig(caFile, certFile, keyFile string, insecure bool) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: insecure}
+	if caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found from %s", caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("both -tls-cert and -tls-key are needed")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// GetMediaType returns the media type.
+func (g *GXTLSNet) GetMediaType() string {
+	return "TLS"
+}
+
+// Validate checks that TCP is used. TLS can't be used over UDP.
+func (g *GXTLSNet) Validate() error {
+	if g.Protocol != gxnet.NetworkTypeTCP {
+		return errors.New("TLS needs TCP connection")
+	}
+	return nil
+}
+
+// Open connects to the host and makes the TLS handshake.
+func (g *GXTLSNet) Open() error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn != nil {
+		return nil
+	}
+	dialer := &net.Dialer{Timeout: time.Duration(g.GetTimeout()) * time.Millisecond}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(strings.Trim(g.HostName, "[]"), strconv.Itoa(g.Port)), g.Config)
+	if err != nil {
+		return err
+	}
+	g.conn = conn
+	g.buf = nil
+	go g.reader(conn)
+	return nil
+}
+
+// IsOpen returns true if the TLS connection is open.
+func (g *GXTLSNet) IsOpen() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.conn != nil
+}
+
+// Close closes the TLS connection.
+func (g *GXTLSNet) Close() error {
+	g.mu.Lock()
+	conn := g.conn
+	g.conn = nil
+	g.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// Send sends data to the meter.
+func (g *GXTLSNet) Send(data any, receiver string) error {
+	tmp, err := gxcommon.ToBytes(data, binary.BigEndian)
+	if err != nil {
+		return err
+	}
+	g.mu.Lock()
+	conn := g.conn
+	g.mu.Unlock()
+	if conn == nil {
+		return gxcommon.ErrConnectionClosed
+	}
+	if timeout := g.GetTimeout(); timeout != 0 {
+		_ = conn.SetWriteDeadline(time.Now().Add(time.Duration(timeout) * time.Millisecond))
+	}
+	n, err := conn.Write(tmp)
+	g.mu.Lock()
+	g.bytesSent += uint64(n)
+	g.mu.Unlock()
+	return err
+}
+
+// Receive waits until the end of packet or count bytes are received.
+func (g *GXTLSNet) Receive(args *gxcommon.ReceiveParameters) (bool, error) {
+	if args.EOP == nil && args.Count == 0 && !args.AllData {
+		return false, errors.New("either Count or EOP must be set")
+	}
+	eop, err := gxcommon.ToBytes(args.EOP, binary.BigEndian)
+	if err != nil {
+		return false, err
+	}
+	var deadline <-chan time.Time
+	if args.WaitTime > 0 {
+		t := time.NewTimer(time.Duration(args.WaitTime) * time.Millisecond)
+		defer t.Stop()
+		deadline = t.C
+	}
+	for {
+		g.mu.Lock()
+		if index := packetEnd(g.buf, args, eop); index != -1 {
+			data := bytes.Clone(g.buf[:index])
+			if !args.Peek {
+				g.buf = g.buf[index:]
+			}
+			g.mu.Unlock()
+			args.Reply, err = gxcommon.BytesToAny2(data, args.ReplyType, binary.BigEndian)
+			return err == nil, err
+		}
+		ch := g.wait
+		g.mu.Unlock()
+		if args.WaitTime == 0 {
+			return false, nil
+		}
+		select {
+		case <-ch:
+		case <-deadline:
+			return false, nil
+		}
+	}
+}
+
+// packetEnd returns the end index of the packet in the buffer or -1 if the packet is not received yet.
+func packetEnd(buf []byte, args *gxcommon.ReceiveParameters, eop []byte) int {
+	if len(buf) < args.Count || len(buf) == 0 {
+		return -1
+	}
+	index := max(args.Count, 1)
+	if len(eop) != 0 {
+		//End of packet is searched after count bytes.
+		start := max(args.Count-len(eop), 0)
+		pos := bytes.Index(buf[start:], eop)
+		if pos == -1 {
+			return -1
+		}
+		index = start + pos + len(eop)
+	}
+	if args.AllData {
+		index = len(buf)
+	}
+	return index
+}
+
+// reader reads data from the connection until it's closed.
+func (g *GXTLSNet) reader(conn *tls.Conn) {
+	buf := make([]byte, 1518)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			g.handleData(buf[:n])
+		}
+		if err != nil {
+			g.mu.Lock()
+			closed := g.conn != conn
+			onError := g.onError
+			g.mu.Unlock()
+			if !closed && onError != nil {
+				onError(g, err)
+			}
+			return
+		}
+	}
+}
+
+// handleData adds received data to the buffer or notifies it if the media is not synchronous.
+func (g *GXTLSNet) handleData(data []byte) {
+	g.mu.Lock()
+	g.bytesReceived += uint64(len(data))
+	if !g.synchronous && g.onReceive != nil {
+		onReceive := g.onReceive
+		g.mu.Unlock()
+		onReceive(g, *gxcommon.NewReceiveEventArgs(bytes.Clone(data), g.GetName()))
+		return
+	}
+	g.buf = append(g.buf, data...)
+	old := g.wait
+	g.wait = make(chan struct{})
+	g.mu.Unlock()
+	close(old)
+}
+
+// SetOnReceived sets the callback for asynchronously received data.
+func (g *GXTLSNet) SetOnReceived(value gxcommon.ReceivedEventHandler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onReceive = value
+}
+
+// SetOnError sets the callback for connection errors.
+func (g *GXTLSNet) SetOnError(value gxcommon.ErrorEventHandler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onError = value
+}
+
+// GetSynchronous enables synchronous receive mode and returns a function that restores it.
+func (g *GXTLSNet) GetSynchronous() func() {
+	g.mu.Lock()
+	g.synchronous = true
+	g.mu.Unlock()
+	return func() {
+		g.mu.Lock()
+		g.synchronous = false
+		g.mu.Unlock()
+	}
+}
+
+// IsSynchronous returns true if synchronous receive mode is enabled.
+func (g *GXTLSNet) IsSynchronous() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.synchronous
+}
+
+// ResetSynchronousBuffer clears received data.
+func (g *GXTLSNet) ResetSynchronousBuffer() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.buf = nil
+}
+
+// GetBytesSent returns the number of sent bytes.
+func (g *GXTLSNet) GetBytesSent() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.bytesSent
+}
+
+// GetBytesReceived returns the number of received bytes.
+func (g *GXTLSNet) GetBytesReceived() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.bytesReceived
+}
+
+// ResetByteCounters resets sent and received byte counters.
+func (g *GXTLSNet) ResetByteCounters() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.bytesSent = 0
+	g.bytesReceived = 0
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+)
+
+// UnitMode defines how energy and power values are scaled when they are shown.
+type UnitMode int
+
+const (
+	// UnitModeNone shows values as they are read.
+	UnitModeNone UnitMode = iota
+	// UnitModeSI shows values in base units, e.g. Wh.
+	UnitModeSI
+	// UnitModeKilo shows values in kilo units, e.g. kWh.
+	UnitModeKilo
+	// UnitModeAuto selects the magnitude that gives a readable number.
+	UnitModeAuto
+)
+
+// UnitModeParse parses unit mode from the string.
+func UnitModeParse(value string) (UnitMode, error) {
+	switch strings.ToLower(value) {
+	case "si":
+		return UnitModeSI, nil
+	case "kilo":
+		return UnitModeKilo, nil
+	case "auto":
+		return UnitModeAuto, nil
+	default:
+		return UnitModeNone, fmt.Errorf("invalid unit mode %q (si, kilo, auto)", value)
+	}
+}
+
+// unitSymbols contains the units that can be scaled to the other magnitudes.
+var unitSymbols = map[enums.Unit]string{
+	enums.UnitActiveEnergy:   "Wh",
+	enums.UnitApparentEnergy: "VAh",
+	enums.UnitReactiveEnergy: "varh",
+	enums.UnitActivePower:    "W",
+	enums.UnitApparentPower:  "VA",
+	enums.UnitReactivePower:  "var",
+}
+
+// unitPrefixes are the magnitudes that auto mode can select.
+var unitPrefixes = []struct {
+	prefix string
+	factor float64
+}{
+	{"G", 1e9},
+	{"M", 1e6},
+	{"k", 1e3},
+	{"", 1},
+}
+
+// scaleUnit returns the value in the magnitude that the mode selects and the unit symbol.
+func scaleUnit(value float64, unit enums.Unit, mode UnitMode) (float64, string) {
+	symbol, ok := unitSymbols[unit]
+	if !ok {
+		return value, unit.String()
+	}
+	switch mode {
+	case UnitModeKilo:
+		return value / 1e3, "k" + symbol
+	case UnitModeAuto:
+		for _, it := range unitPrefixes {
+			if math.Abs(value) >= it.factor {
+				return value / it.factor, it.prefix + symbol
+			}
+		}
+	}
+	return value, symbol
+}
+
+// toFloat converts a numeric value to float64.
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// objectUnit returns the unit of the register value attributes.
+func objectUnit(obj objects.IGXDLMSBase, index int) (enums.Unit, bool) {
+	switch o := obj.(type) {
+	case *objects.GXDLMSRegister:
+		return o.Unit, index == 2
+	case *objects.GXDLMSExtendedRegister:
+		return o.Unit, index == 2
+	case *objects.GXDLMSDemandRegister:
+		return o.Unit, index == 2 || index == 3
+	}
+	return enums.UnitNone, false
+}
+
+// objectScaler returns the scaler of the register.
+func objectScaler(obj objects.IGXDLMSBase) float64 {
+	switch o := obj.(type) {
+	case *objects.GXDLMSRegister:
+		return o.Scaler
+	case *objects.GXDLMSExtendedRegister:
+		return o.Scaler
+	case *objects.GXDLMSDemandRegister:
+		return o.Scaler
+	}
+	return 1
+}
+
+// FormatWithScaler returns the register value with the unit and the scaler that is read with
+// GetScalersAndUnits. The library has already multiplied the value by the scaler, so the value is
+// formatted as it is and the scaler is shown only as an annotation. Ex. 1.234 kWh (scaler 10^-3).
+func (r *GXDLMSReader) FormatWithScaler(obj objects.IGXDLMSBase, value any) string {
+	v, ok := toFloat(value)
+	if !ok {
+		return r.formatValue(value)
+	}
+	unit, _ := objectUnit(obj, 2)
+	v, symbol := scaleUnit(v, unit, r.UnitMode)
+	ret := formatFloat(v)
+	if unit != enums.UnitNone {
+		ret += " " + symbol
+	}
+	if scaler := objectScaler(obj); scaler > 0 {
+		if exp := int(math.Round(math.Log10(scaler))); exp != 0 {
+			ret += fmt.Sprintf(" (scaler 10^%d)", exp)
+		}
+	}
+	return ret
+}
+
+// displayValue converts energy and power values to the selected magnitude.
+// Only the shown value is converted. The value of the object is not changed.
+func (r *GXDLMSReader) displayValue(obj objects.IGXDLMSBase, index int, value any) any {
+	if dr, ok := obj.(*objects.GXDLMSDemandRegister); ok {
+		return r.displayDemandValue(dr, index, value)
+	}
+	unit, ok := objectUnit(obj, index)
+	if !ok {
+		return value
+	}
+	if r.ApplyScaler {
+		return r.FormatWithScaler(obj, value)
+	}
+	if r.UnitMode == UnitModeNone {
+		return value
+	}
+	v, ok := toFloat(value)
+	if !ok {
+		return value
+	}
+	v, symbol := scaleUnit(v, unit, r.UnitMode)
+	return fmt.Sprintf("%s %s", formatFloat(v), symbol)
+}
+
+// displayDemandValue shows the current and last average values of the demand register always with
+// the unit and the scaler, because the values are meaningless for the peak demand billing without them.
+// Period is shown in seconds.
+func (r *GXDLMSReader) displayDemandValue(dr *objects.GXDLMSDemandRegister, index int, value any) any {
+	switch index {
+	case 2, 3:
+		return r.FormatWithScaler(dr, value)
+	case 8:
+		if v, ok := toFloat(value); ok {
+			return fmt.Sprintf("%s s", formatFloat(v))
+		}
+	}
+	return value
+}
+
+// attributeReadOrder returns the attribute indexes of the object in the order they are read.
+// Scaler and unit of the demand register are read first so the average values can be scaled.
+func attributeReadOrder(obj objects.IGXDLMSBase) []int {
+	indexes := obj.GetAttributeIndexToRead(true)
+	if obj.Base().ObjectType() != enums.ObjectTypeDemandRegister {
+		return indexes
+	}
+	ret := make([]int, 0, len(indexes))
+	for _, pos := range indexes {
+		if pos == 4 {
+			ret = append([]int{pos}, ret...)
+		} else {
+			ret = append(ret, pos)
+		}
+	}
+	return ret
+}
+
+// formatFloat formats float without trailing zeros.
+func formatFloat(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", v), "0"), ".")
+}
+
+// valueWithUnit returns the register value with the unit.
+func (r *GXDLMSReader) valueWithUnit(obj objects.IGXDLMSBase, index int, value any) string {
+	if unit, ok := objectUnit(obj, index); ok {
+		_, demand := obj.(*objects.GXDLMSDemandRegister)
+		if r.UnitMode != UnitModeNone || r.ApplyScaler || demand {
+			return fmt.Sprint(r.displayValue(obj, index, value))
+		}
+		return fmt.Sprintf("%v %s", value, unit.String())
+	}
+	return r.formatValue(value)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/Gurux/gxcommon-go"
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+)
+
+// The library has already multiplied the value by the scaler, so FormatWithScaler must not do it again.
+func TestFormatWithScaler(t *testing.T) {
+	tests := []struct {
+		name     string
+		unitMode UnitMode
+		value    any
+		expected string
+	}{
+		{"value is shown as it is", UnitModeNone, 1.234, "1.234 Wh (scaler 10^-3)"},
+		{"unit mode is applied to scaled value", UnitModeKilo, 1234.0, "1.234 kWh (scaler 10^-3)"},
+		{"value is not a number", UnitModeNone, "abc", "abc"},
+	}
+	reg, err := objects.NewGXDLMSRegister("1.0.1.8.0.255", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.Scaler = 0.001
+	reg.Unit = enums.UnitActiveEnergy
+	r := NewGXDLMSReader(nil, nil, gxcommon.TraceLevelOff, "", 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.UnitMode = tt.unitMode
+			if got := r.FormatWithScaler(reg, tt.value); got != tt.expected {
+				t.Errorf("returned %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
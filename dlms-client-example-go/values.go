@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gurux/gxdlms-go/enums"
+	"github.com/Gurux/gxdlms-go/objects"
+	"github.com/Gurux/gxdlms-go/types"
+)
+
+// parseValue converts the command line value to the given DLMS data type.
+func parseValue(value string, dt enums.DataType) (any, error) {
+	var err error
+	var ret any
+	switch dt {
+	case enums.DataTypeBoolean:
+		ret, err = strconv.ParseBool(value)
+	case enums.DataTypeInt8:
+		var v int64
+		v, err = strconv.ParseInt(value, 0, 8)
+		ret = int8(v)
+	case enums.DataTypeInt16:
+		var v int64
+		v, err = strconv.ParseInt(value, 0, 16)
+		ret = int16(v)
+	case enums.DataTypeInt32:
+		var v int64
+		v, err = strconv.ParseInt(value, 0, 32)
+		ret = int32(v)
+	case enums.DataTypeInt64:
+		ret, err = strconv.ParseInt(value, 0, 64)
+	case enums.DataTypeUint8, enums.DataTypeEnum:
+		var v uint64
+		v, err = strconv.ParseUint(value, 0, 8)
+		ret = uint8(v)
+	case enums.DataTypeUint16:
+		var v uint64
+		v, err = strconv.ParseUint(value, 0, 16)
+		ret = uint16(v)
+	case enums.DataTypeUint32:
+		var v uint64
+		v, err = strconv.ParseUint(value, 0, 32)
+		ret = uint32(v)
+	case enums.DataTypeUint64:
+		ret, err = strconv.ParseUint(value, 0, 64)
+	case enums.DataTypeFloat32:
+		var v float64
+		v, err = strconv.ParseFloat(value, 32)
+		ret = float32(v)
+	case enums.DataTypeFloat64:
+		ret, err = strconv.ParseFloat(value, 64)
+	case enums.DataTypeOctetString:
+		//Date-times are written as octet strings.
+		if !strings.HasPrefix(value, "0x") && !strings.HasPrefix(value, "0X") {
+			if t, e := parseTime(value); e == nil && !t.IsZero() {
+				ret = *types.NewGXDateTimeFromTime(t)
+				break
+			}
+		}
+		ret = types.HexToBytes(strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X"))
+	case enums.DataTypeDateTime:
+		var t time.Time
+		if t, err = parseTime(value); err == nil && t.IsZero() {
+			err = fmt.Errorf("open-ended time is not allowed")
+		}
+		ret = *types.NewGXDateTimeFromTime(t)
+	case enums.DataTypeString, enums.DataTypeStringUTF8:
+		ret = value
+	case enums.DataTypeNone:
+		return nil, fmt.Errorf("data type of the value %q is not known", value)
+	default:
+		return nil, fmt.Errorf("data type %s is not supported", dt.String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q", dt.String(), value)
+	}
+	return ret, nil
+}
+
+// methodDataType returns the parameter data type of the method.
+// DataTypeNone is returned if the type is not known.
+func methodDataType(obj objects.IGXDLMSBase, index int) enums.DataType {
+	switch obj.Base().ObjectType() {
+	case enums.ObjectTypeClock:
+		//Shift time takes the seconds. Other methods take zero.
+		if index == 6 {
+			return enums.DataTypeInt16
+		}
+		return enums.DataTypeInt8
+	case enums.ObjectTypeScriptTable:
+		return enums.DataTypeUint16
+	case enums.ObjectTypeDisconnectControl, enums.ObjectTypeRegister,
+		enums.ObjectTypeExtendedRegister, enums.ObjectTypeDemandRegister,
+		enums.ObjectTypeProfileGeneric:
+		return enums.DataTypeInt8
+	}
+	return enums.DataTypeNone
+}
+
+// parseMethodValue converts the command line value to the parameter of the method.
+// Methods that don't take a parameter are invoked with zero.
+func parseMethodValue(obj objects.IGXDLMSBase, index int, value string) (any, error) {
+	dt := methodDataType(obj, index)
+	if value == "" {
+		if dt != enums.DataTypeNone && dt != enums.DataTypeInt8 {
+			return nil, fmt.Errorf("method %d of %s needs a %s value", index, obj.Base().LogicalName(), dt.String())
+		}
+		return int8(0), nil
+	}
+	if dt == enums.DataTypeNone {
+		//Parameter type is guessed from the value.
+		if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+			dt = enums.DataTypeOctetString
+		} else if _, err := strconv.ParseInt(value, 0, 32); err == nil {
+			dt = enums.DataTypeInt32
+		} else {
+			dt = enums.DataTypeString
+		}
+	}
+	return parseValue(value, dt)
+}
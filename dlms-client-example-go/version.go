@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// version is the version of the example. It's set when the example is built:
+// go build -ldflags "-X main.version=1.0.0"
+var version = "dev"
+
+// showVersion shows the version of the example, Go and the Gurux libraries.
+func showVersion() {
+	fmt.Printf("gxdlms-client-example-go %s\n", version)
+	fmt.Printf("Go %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		fmt.Println("Library versions are not available.")
+		return
+	}
+	for _, it := range info.Deps {
+		if !strings.HasPrefix(it.Path, "github.com/Gurux/") {
+			continue
+		}
+		v := it.Version
+		if it.Replace != nil {
+			v += " => " + it.Replace.Path + " " + it.Replace.Version
+		}
+		fmt.Printf("%s %s\n", strings.TrimPrefix(it.Path, "github.com/Gurux/"), v)
+	}
+}